@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"mdnav/internal/core"
+)
+
+// Command 命令行子命令
+type Command struct {
+	Name  string
+	Usage string                                     // 一行说明
+	Run   func(ctx *core.Context, args []string) int // 返回进程退出码
+}
+
+var commands = make(map[string]Command)
+
+// Register 注册子命令
+func Register(c Command) {
+	commands[c.Name] = c
+}
+
+// Run 执行子命令，name 未注册时返回 false
+func Run(ctx *core.Context, args []string) (code int, ok bool) {
+
+	if len(args) == 0 {
+		return 0, false
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		PrintUsage(os.Stdout)
+		return 0, true
+	}
+
+	c, ok := commands[args[0]]
+	if !ok {
+		return 0, false
+	}
+
+	return c.Run(ctx, args[1:]), true
+}
+
+// PrintUsage 输出所有子命令
+func PrintUsage(w io.Writer) {
+
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "用法: mdnav [命令] [参数]")
+	fmt.Fprintln(w, "不带命令时启动服务，debug 启动服务并监听文件变化")
+	fmt.Fprintln(w)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-18s %s\n", name, commands[name].Usage)
+	}
+}
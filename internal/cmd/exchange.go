@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/exchange"
+	"mdnav/internal/service"
+)
+
+func init() {
+
+	usages := map[string]string{
+		exchange.FormatBookmarks: "浏览器书签 HTML",
+		exchange.FormatCsv:       "CSV",
+		exchange.FormatOpml:      "OPML",
+		exchange.FormatAwesome:   "awesome-* 风格的 README",
+	}
+
+	for _, format := range exchange.Formats {
+		Register(Command{
+			Name:  "import-" + format,
+			Usage: "导入" + usages[format] + "，-dry-run 只显示将要新建、更新和跳过的内容",
+			Run:   importCommand(format),
+		})
+		Register(Command{
+			Name:  "export-" + format,
+			Usage: "导出为" + usages[format] + "，-o 指定输出文件",
+			Run:   exportCommand(format),
+		})
+	}
+}
+
+func importCommand(format string) func(ctx *core.Context, args []string) int {
+	return func(ctx *core.Context, args []string) int {
+
+		flags := flag.NewFlagSet("import-"+format, flag.ContinueOnError)
+		category := flags.String("category", "imported", "未分组链接所属的分类")
+		dryRun := flags.Bool("dry-run", false, "只生成报告，不写入文件")
+		columns := flags.String("map", "", "CSV 列映射，如 name=标题,url=链接")
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+		if flags.NArg() != 1 {
+			fmt.Fprintf(os.Stderr, "用法: import-%s [-dry-run] [-category imported] 文件\n", format)
+			return 2
+		}
+
+		opts, err := exchangeOptions(ctx, *columns)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		opts.RootCategory = *category
+
+		f, err := os.Open(flags.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer f.Close()
+
+		dir, err := exchange.Parse(format, f, opts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		if err := service.LoadAllData(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		// 书签只新增，其他格式可以原样导回，已有文档按导入数据更新
+		report, err := service.ImportDirectory(ctx, dir, service.ImportOptions{
+			DryRun: *dryRun,
+			Update: format != exchange.FormatBookmarks,
+		})
+		printImportReport(report)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		return 0
+	}
+}
+
+func exportCommand(format string) func(ctx *core.Context, args []string) int {
+	return func(ctx *core.Context, args []string) int {
+
+		flags := flag.NewFlagSet("export-"+format, flag.ContinueOnError)
+		output := flags.String("o", "", "输出文件，默认标准输出")
+		columns := flags.String("map", "", "CSV 列映射，如 name=标题,url=链接")
+		if err := flags.Parse(args); err != nil {
+			return 2
+		}
+
+		opts, err := exchangeOptions(ctx, *columns)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+
+		if err := service.LoadAllData(ctx); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		w := os.Stdout
+		if *output != "" {
+			f, err := os.Create(*output)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			defer f.Close()
+			w = f
+		}
+
+		if err := exchange.Write(format, w, service.ExportDirectory(), opts); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		return 0
+	}
+}
+
+// exchangeOptions 读取 exchange.csv_columns 配置，columns 参数中的映射优先
+func exchangeOptions(ctx *core.Context, columns string) (exchange.Options, error) {
+	mapping, err := service.CsvMapping(ctx, columns)
+	return exchange.Options{
+		CsvMapping:  mapping,
+		Title:       ctx.Conf.GetString("site.name"),
+		Description: ctx.Conf.GetString("site.description"),
+	}, err
+}
+
+func printImportReport(report *service.ImportReport) {
+	if report == nil {
+		return
+	}
+	prefix := ""
+	if report.DryRun {
+		prefix = "[dry-run] "
+	}
+	for _, v := range report.Categories {
+		fmt.Println(prefix+"新建分类", v)
+	}
+	for _, v := range report.Created {
+		fmt.Println(prefix+"新建文档", v.Slug)
+	}
+	for _, v := range report.Updated {
+		fmt.Printf("%s更新文档 %s：%s\n", prefix, v.Slug, v.Reason)
+	}
+	for _, v := range report.Skipped {
+		fmt.Printf("%s跳过 %s (%s)：%s\n", prefix, v.Name, v.Url, v.Reason)
+	}
+	fmt.Printf("%s共新建 %d 个分类、%d 个文档，更新 %d 个，跳过 %d 个\n", prefix, len(report.Categories), len(report.Created), len(report.Updated), len(report.Skipped))
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"mdnav/internal/core"
+	"mdnav/internal/service"
+)
+
+func init() {
+	Register(Command{
+		Name:  "fetch-icons",
+		Usage: "获取全部文档的网站图标并写入缓存，可在离线模式前预先执行",
+		Run:   runFetchIcons,
+	})
+}
+
+func runFetchIcons(ctx *core.Context, args []string) int {
+
+	service.InitIcons(ctx)
+	if !service.EvictIcons() {
+		fmt.Fprintln(os.Stderr, "未启用 icons.enabled")
+		return 2
+	}
+
+	if err := service.LoadAllData(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	fetched, failed := service.PrefetchIcons(context.Background())
+	fmt.Printf("已缓存 %d 个图标，%d 个获取失败\n", fetched, failed)
+
+	return 0
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"mdnav/internal/core"
+	"mdnav/internal/service"
+)
+
+func init() {
+	Register(Command{
+		Name:  "check-links",
+		Usage: "立即检查全部文档的链接并保存结果，存在失效链接时退出码为1",
+		Run:   runCheckLinks,
+	})
+}
+
+func runCheckLinks(ctx *core.Context, args []string) int {
+
+	flags := flag.NewFlagSet("check-links", flag.ContinueOnError)
+	asJson := flags.Bool("json", false, "以 JSON 输出失效链接")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := service.LoadAllData(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if err := service.CheckLinks(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	broken := service.GetBrokenLinks()
+
+	if *asJson {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(broken); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	} else {
+		for _, v := range broken {
+			reason := v.Health.Last.Error
+			if reason == "" {
+				reason = fmt.Sprintf("HTTP %d", v.Health.Last.StatusCode)
+			}
+			fmt.Printf("%s\t%s\t%s（连续失败 %d 次）\n", v.Slug, v.Url, reason, v.Health.Failures)
+		}
+		fmt.Printf("失效链接 %d 个\n", len(broken))
+	}
+
+	if len(broken) > 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"mdnav/internal/core"
+	"mdnav/internal/lint"
+)
+
+func init() {
+	Register(Command{
+		Name:  "lint",
+		Usage: "检查内容目录，-format text|json|sarif，存在错误时退出码为1",
+		Run:   runLint,
+	})
+}
+
+func runLint(ctx *core.Context, args []string) int {
+
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	format := flags.String("format", lint.FormatText, "输出格式：text、json、sarif")
+	dir := flags.String("dir", ctx.Conf.GetString("server.content_dir"), "内容目录")
+	output := flags.String("o", "", "输出文件，默认标准输出")
+	strict := flags.Bool("strict", false, "警告也视为失败")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	report, err := lint.Run(*dir, lint.Options{
+		KnownTags: ctx.Conf.GetStringSlice("lint.tags"),
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	w := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := report.Write(w, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	if report.HasErrors(*strict) {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/service"
+)
+
+func init() {
+	Register(Command{
+		Name:  "new",
+		Usage: "抓取网页标题、描述等信息，在分类目录下新建链接文档，-dry-run 只显示生成的内容",
+		Run:   runNew,
+	})
+}
+
+func runNew(ctx *core.Context, args []string) int {
+
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	category := flags.String("category", "", "分类 slug 或名称，不存在时新建")
+	slug := flags.String("slug", "", "文件名，默认由名称或域名生成")
+	name := flags.String("name", "", "名称，默认使用网页标题")
+	description := flags.String("description", "", "描述，默认使用网页描述")
+	tags := flags.String("tags", "", "标签，逗号分隔")
+	dryRun := flags.Bool("dry-run", false, "只显示生成的内容，不写入文件")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 || *category == "" {
+		fmt.Fprintln(os.Stderr, "用法: new -category 分类 [-name 名称] [-tags a,b] [-dry-run] 网址")
+		return 2
+	}
+
+	if err := service.LoadAllData(ctx); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	result, err := service.NewLink(ctx, service.NewLinkOptions{
+		Url:         flags.Arg(0),
+		Category:    *category,
+		Slug:        *slug,
+		Name:        *name,
+		Description: *description,
+		Tags:        markdown.SplitTags(*tags),
+		DryRun:      *dryRun,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if result.FetchError != "" {
+		fmt.Fprintf(os.Stderr, "抓取网页失败，只使用指定的信息：%s\n", result.FetchError)
+	}
+	for _, v := range result.Categories {
+		fmt.Printf("新建分类 %s\n", v)
+	}
+	if *dryRun {
+		fmt.Printf("将新建 %s\n\n%s", result.Slug, result.Content)
+	} else {
+		fmt.Printf("已新建 %s\n", result.Slug)
+	}
+
+	return 0
+}
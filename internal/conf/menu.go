@@ -0,0 +1,56 @@
+package conf
+
+import (
+	"net/url"
+	"strings"
+)
+
+// 菜单位置
+const (
+	MenuHeader = "header" // 页面顶部，侧边导航上方
+	MenuFooter = "footer" // 页脚
+)
+
+// MenuItem 菜单项，Url 为站内路径或外部链接，Page 为站内页面的 slug
+type MenuItem struct {
+	Name     string `json:"name" mapstructure:"name"`
+	Url      string `json:"url" mapstructure:"url"`
+	Page     string `json:"page" mapstructure:"page"`
+	Weight   int    `json:"weight" mapstructure:"weight"` // 越小越靠前
+	External bool   `json:"external" mapstructure:"-"`    // 是否为外部链接，根据 Url 判断
+}
+
+// Menus 站点菜单，键为菜单位置
+type Menus map[string][]MenuItem
+
+// GetMenus 读取 menus 配置，设置了 Page 但没有 Url 的菜单项指向 /<page>
+func GetMenus() (Menus, error) {
+
+	menus := Menus{}
+	if config == nil {
+		return menus, nil
+	}
+
+	if err := config.UnmarshalKey("menus", &menus); err != nil {
+		return nil, err
+	}
+
+	for _, items := range menus {
+		for i := range items {
+			items[i].Normalize()
+		}
+	}
+
+	return menus, nil
+}
+
+// Normalize 补全 Url 并判断是否为外部链接
+func (m *MenuItem) Normalize() {
+
+	if m.Url == "" && m.Page != "" {
+		m.Url = "/" + strings.Trim(m.Page, "/")
+	}
+
+	u, err := url.Parse(m.Url)
+	m.External = err == nil && (u.Scheme != "" || u.Host != "")
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"mdnav/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApiDocuments 文档列表接口，支持 ?q= 过滤表达式
+func (h *Handler) ApiDocuments(ctx *gin.Context) {
+
+	query, err := parseQuery(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result: Result{
+			Data:  service.QueryDocuments(query),
+			Query: query.Raw,
+		},
+	})
+}
+
+// ApiCategories 分类列表接口
+func (h *Handler) ApiCategories(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Categories: service.GetAllCategories()},
+	})
+}
+
+// ApiTags 标签列表接口
+func (h *Handler) ApiTags(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Tags: service.GetAllTags()},
+	})
+}
+
+// ApiCollection 合集接口
+func (h *Handler) ApiCollection(ctx *gin.Context) {
+
+	data := service.GetCollection(strings.TrimPrefix(ctx.Param("slug"), "/"))
+	if data == nil {
+		ctx.JSON(http.StatusNotFound, Response{Status: 1, Message: http.StatusText(http.StatusNotFound)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: data},
+	})
+}
@@ -32,12 +32,15 @@ func (h *Handler) Article(ctx *gin.Context) {
 
 	result := Result{
 		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
 		Data:       data,
+		Category:   data.Category,
 		Categories: service.GetAllCategories(),
+		LinkStatus: service.GetLinkStatuses(),
 		Tags:       service.GetAllTags(),
 	}
 
-	bytes, err := tpl.Render(h.TplDir, "article.html", result)
+	bytes, err := tpl.Render(pageTemplate("article", data.Document.Layout, data.Document.Template), result)
 	if err != nil {
 		h.Ctx.Log.Error(err.Error())
 		ctx.AbortWithError(http.StatusInternalServerError, err)
@@ -1,13 +1,18 @@
 package handler
 
 import (
+	"path"
+
 	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/utils/tpl"
+
+	"github.com/gin-gonic/gin"
 )
 
 // Handler HTTP请求处理器结构体，包含应用上下文
 type Handler struct {
-	Ctx    *core.Context // 应用上下文，包含日志记录器等核心组件
-	TplDir string
+	Ctx *core.Context // 应用上下文，包含日志记录器等核心组件
 }
 
 // JsonResponse JSON响应结构体
@@ -22,6 +27,31 @@ type Result struct {
 	Data       any    `json:"data"`       // 页面数据，根据请求返回对应的数据
 	Categories any    `json:"categories"` // 页面所有分类数据
 	Category   any    `json:"category"`
-	Tags       any    `json:"tags"` // 所有tags
-	Tag        string `json:"tag"`  //
+	Tags       any    `json:"tags"`     // 所有tags
+	Tag        string `json:"tag"`      //
+	Listing    string `json:"listing"`  // 列表页名称：latest（最近添加）、updated（最近更新）
+	Query      string `json:"query"`    // 过滤表达式 ?q=
+	Sections   any    `json:"sections"` // 首页区块
+	Preview    bool   `json:"preview"`  // 是否为草稿预览
+	Menus      any    `json:"menus"`    // 站点菜单，键为菜单位置（header、footer）
+	// 链接检查状态 url -> ok/broken，未检查的链接不在其中
+	LinkStatus map[string]string `json:"link_status"`
+}
+
+// pageTemplate 按 front matter 选择页面模板：template 指定的文件，其次是 <kind>-<layout>.html，最后是 <kind>.html
+func pageTemplate(kind, layout, name string) string {
+
+	if name != "" && path.Ext(name) == "" {
+		name += ".html"
+	}
+	if layout != "" {
+		layout = kind + "-" + layout + ".html"
+	}
+
+	return tpl.Pick(name, layout, kind+".html")
+}
+
+// parseQuery 解析请求中的 ?q= 过滤表达式
+func parseQuery(ctx *gin.Context) (*doc.Query, error) {
+	return doc.ParseQuery(ctx.Query("q"))
 }
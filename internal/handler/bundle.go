@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"net/http"
+
+	"mdnav/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bundle 输出与文档放在同一目录中的资源文件，如 /bundle/ai-tools/openai.png
+func (h *Handler) Bundle(ctx *gin.Context) {
+
+	file, info, err := service.OpenBundleFile(h.Ctx, ctx.Param("path"))
+	if err != nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	// 内容类型按扩展名确定，禁止浏览器猜测；资源中的脚本（如 svg）不执行
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; sandbox")
+	http.ServeContent(ctx.Writer, ctx.Request, info.Name(), info.ModTime(), file)
+}
@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"mdnav/internal/models/doc"
 	"mdnav/internal/service"
 	"mdnav/internal/utils/tpl"
 	"net/http"
@@ -13,12 +12,23 @@ func (h *Handler) Category(ctx *gin.Context) {
 
 	params := ctx.Param("slug")
 
-	data := service.GetCategoryDocumentsByCateSlug(params, doc.SortByUpdateTime, doc.Ascending)
+	query, err := parseQuery(ctx)
+	if err != nil {
+		ctx.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	data := service.GetCategoryDocuments(params)
 	if data == nil {
-		ctx.AbortWithStatus(404)
+		// 不是分类时按独立页面处理
+		h.Page(ctx)
 		return
 	}
 
+	if !query.IsEmpty() {
+		data.DocumentList = query.Apply(data.DocumentList)
+	}
+
 	// result := Response{
 	// 	Status:  0,
 	// 	Message: "success",
@@ -32,12 +42,15 @@ func (h *Handler) Category(ctx *gin.Context) {
 
 	result := Result{
 		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
 		Data:       data,
 		Categories: service.GetAllCategories(),
 		Category:   service.GetCategoryBySlug(params),
+		Query:      query.Raw,
+		LinkStatus: service.GetLinkStatuses(),
 	}
 
-	bytes, err := tpl.Render(h.TplDir, "category.html", result)
+	bytes, err := tpl.Render(pageTemplate("category", data.Category.Layout, data.Category.Template), result)
 	if err != nil {
 		h.Ctx.Log.Error(err.Error())
 		ctx.AbortWithError(http.StatusInternalServerError, err)
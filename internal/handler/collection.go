@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) Collection(ctx *gin.Context) {
+
+	params := strings.TrimPrefix(ctx.Param("slug"), "/")
+
+	data := service.GetCollection(params)
+	if data == nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	result := Result{
+		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
+		Data:       data,
+		Categories: service.GetAllCategories(),
+		LinkStatus: service.GetLinkStatuses(),
+	}
+
+	bytes, err := tpl.Render("collection.html", result)
+	if err != nil {
+		h.Ctx.Log.Error(err.Error())
+		ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(bytes)
+}
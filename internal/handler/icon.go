@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"mdnav/internal/pkg/favicon"
+	"mdnav/internal/pkg/zap"
+	"mdnav/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Icon 输出网站图标，?src= 为文档 icon 远程地址的短代码
+func (h *Handler) Icon(ctx *gin.Context) {
+
+	file, err := service.ResolveIcon(ctx.Request.Context(), ctx.Param("host"), ctx.Query("src"))
+	if err != nil {
+		if !errors.Is(err, favicon.ErrNotFound) {
+			h.Ctx.Log.Error("获取图标失败", zap.String("host", ctx.Param("host")), zap.Error(err))
+		}
+		ctx.Header("Cache-Control", "public, max-age=3600")
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.File(file)
+}
@@ -11,7 +11,13 @@ import (
 
 func (h *Handler) Index(ctx *gin.Context) {
 
-	data := service.GetCategoriesDocuments(doc.SortBySort, doc.Descending)
+	query, err := parseQuery(ctx)
+	if err != nil {
+		ctx.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	data := service.FilterCategoriesDocuments(service.GetCategoriesDocuments(doc.SortBySort, doc.Descending), query)
 	if data == nil {
 		ctx.AbortWithStatus(404)
 		return
@@ -30,12 +36,16 @@ func (h *Handler) Index(ctx *gin.Context) {
 
 	result := Result{
 		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
 		Data:       data,
 		Categories: service.GetAllCategories(),
 		// Tags:       service.GetAllTags(),
+		Query:      query.Raw,
+		Sections:   service.GetHomeSections(h.Ctx, data, query),
+		LinkStatus: service.GetLinkStatuses(),
 	}
 
-	bytes, err := tpl.Render(h.TplDir, "index.html", result)
+	bytes, err := tpl.Render("index.html", result)
 	if err != nil {
 		h.Ctx.Log.Error(err.Error())
 		ctx.AbortWithError(http.StatusInternalServerError, err)
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"mdnav/internal/models/doc"
+	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultPageSize = 24
+
+// Latest 最近添加的文档，按创建时间倒序分页
+func (h *Handler) Latest(ctx *gin.Context) {
+	h.pageDocuments(ctx, "latest", doc.SortByCreateTime)
+}
+
+// Updated 最近更新的文档，按更新时间倒序分页
+func (h *Handler) Updated(ctx *gin.Context) {
+	h.pageDocuments(ctx, "updated", doc.SortByUpdateTime)
+}
+
+// ApiLatest 最近添加的文档接口
+func (h *Handler) ApiLatest(ctx *gin.Context) {
+	h.apiPageDocuments(ctx, doc.SortByCreateTime)
+}
+
+// ApiUpdated 最近更新的文档接口
+func (h *Handler) ApiUpdated(ctx *gin.Context) {
+	h.apiPageDocuments(ctx, doc.SortByUpdateTime)
+}
+
+func (h *Handler) pageDocuments(ctx *gin.Context, name string, sortBy doc.SortBy) {
+
+	page, pageSize := pageParams(ctx)
+
+	result := Result{
+		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
+		Data:       service.GetPageDocuments(page, pageSize, sortBy, doc.Descending),
+		Categories: service.GetAllCategories(),
+		Listing:    name,
+		LinkStatus: service.GetLinkStatuses(),
+	}
+
+	bytes, err := tpl.Render(name+".html", result)
+	if err != nil {
+		h.Ctx.Log.Error(err.Error())
+		ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(bytes)
+}
+
+func (h *Handler) apiPageDocuments(ctx *gin.Context, sortBy doc.SortBy) {
+
+	page, pageSize := pageParams(ctx)
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: service.GetPageDocuments(page, pageSize, sortBy, doc.Descending)},
+	})
+}
+
+// pageParams 读取分页参数 ?page=&page_size=
+func pageParams(ctx *gin.Context) (int, int) {
+
+	page, _ := strconv.Atoi(ctx.DefaultQuery("page", "1"))
+
+	pageSize, err := strconv.Atoi(ctx.Query("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > 100 {
+		pageSize = 100
+	}
+
+	return page, pageSize
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page 独立页面，按请求路径查找，找不到时返回 404
+func (h *Handler) Page(ctx *gin.Context) {
+
+	method := ctx.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	data := service.GetPage(strings.Trim(ctx.Request.URL.Path, "/"))
+	if data == nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	result := Result{
+		Site:       service.GetSiteInfo(h.Ctx),
+		Data:       data,
+		Categories: service.GetAllCategories(),
+		Menus:      service.GetMenus(),
+	}
+
+	bytes, err := tpl.Render(pageTemplate("page", data.Layout, data.Template), result)
+	if err != nil {
+		h.Ctx.Log.Error(err.Error())
+		ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(bytes)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Preview 通过签名链接预览草稿或未发布的文档
+func (h *Handler) Preview(ctx *gin.Context) {
+
+	params := strings.TrimPrefix(ctx.Param("slug"), "/")
+
+	if err := service.VerifyPreview(h.Ctx, params, ctx.Query("expires"), ctx.Query("sig")); err != nil {
+		ctx.AbortWithError(http.StatusForbidden, err)
+		return
+	}
+
+	data := service.GetPreviewDocument(params)
+	if data == nil {
+		ctx.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	result := Result{
+		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
+		Data:       data,
+		Category:   data.Category,
+		Categories: service.GetAllCategories(),
+		LinkStatus: service.GetLinkStatuses(),
+		Tags:       service.GetAllTags(),
+		Preview:    true,
+	}
+
+	bytes, err := tpl.Render(pageTemplate("article", data.Document.Layout, data.Document.Template), result)
+	if err != nil {
+		h.Ctx.Log.Error(err.Error())
+		ctx.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	// 预览页不允许被搜索引擎收录和缓存
+	ctx.Header("X-Robots-Tag", "noindex, nofollow")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Writer.WriteHeader(http.StatusOK)
+	ctx.Writer.Write(bytes)
+}
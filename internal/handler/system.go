@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+
+	"mdnav/internal/pkg/exchange"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SystemScheduled 管理接口：定时发布/过期的文档列表
+func (h *Handler) SystemScheduled(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: service.GetScheduledDocuments()},
+	})
+}
+
+// SystemDocuments 管理接口：全部文档及其状态，草稿等未发布文档附带预览链接
+func (h *Handler) SystemDocuments(ctx *gin.Context) {
+
+	list := service.GetAdminDocuments(h.Ctx)
+
+	// ?status=draft 只看某种状态
+	if status := ctx.Query("status"); status != "" {
+		var filtered []service.AdminDocument
+		for _, v := range list {
+			if v.Status == status {
+				filtered = append(filtered, v)
+			}
+		}
+		list = filtered
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: list},
+	})
+}
+
+// SystemLinks 管理接口：读取链接数据文件 ?file=ai-tools/links.yaml
+func (h *Handler) SystemLinks(ctx *gin.Context) {
+
+	records, err := service.GetLinkRecords(h.Ctx, ctx.Query("file"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: records},
+	})
+}
+
+// SystemSaveLink 管理接口：新增或修改链接数据文件中的一条记录，请求体为 JSON 记录
+func (h *Handler) SystemSaveLink(ctx *gin.Context) {
+
+	var record markdown.LinkRecord
+	if err := ctx.ShouldBindJSON(&record); err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	if err := service.SaveLinkRecord(h.Ctx, ctx.Query("file"), record); err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{Status: 0, Message: "success"})
+}
+
+// SystemDeleteLink 管理接口：删除链接数据文件中的一条记录 ?file=&slug=
+func (h *Handler) SystemDeleteLink(ctx *gin.Context) {
+
+	err := service.DeleteLinkRecord(h.Ctx, ctx.Query("file"), ctx.Query("slug"))
+	if errors.Is(err, os.ErrNotExist) {
+		ctx.JSON(http.StatusNotFound, Response{Status: 1, Message: http.StatusText(http.StatusNotFound)})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{Status: 0, Message: "success"})
+}
+
+// SystemImport 管理接口：上传文件（表单字段 file）并导入，格式为 bookmarks、csv、opml 或 awesome。
+// ?dry_run=1 只返回将要新建、更新和跳过的内容，?category= 指定未分组链接的分类，?map= 指定 CSV 列映射
+func (h *Handler) SystemImport(ctx *gin.Context) {
+
+	format := ctx.Param("format")
+
+	mapping, err := service.CsvMapping(h.Ctx, ctx.Query("map"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+	defer f.Close()
+
+	dir, err := exchange.Parse(format, f, exchange.Options{
+		RootCategory: ctx.DefaultQuery("category", "imported"),
+		CsvMapping:   mapping,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	dryRun := ctx.Query("dry_run") == "1" || ctx.Query("dry_run") == "true"
+	report, err := service.ImportDirectory(h.Ctx, dir, service.ImportOptions{
+		DryRun: dryRun,
+		Update: format != exchange.FormatBookmarks,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, Response{Status: 1, Message: err.Error(), Result: Result{Data: report}})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: report},
+	})
+}
+
+// SystemExport 管理接口：下载导出文件，格式为 bookmarks、csv、opml 或 awesome，?map= 指定 CSV 列映射
+func (h *Handler) SystemExport(ctx *gin.Context) {
+
+	format := ctx.Param("format")
+
+	mapping, err := service.CsvMapping(h.Ctx, ctx.Query("map"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	err = exchange.Write(format, &buf, service.ExportDirectory(), exchange.Options{
+		CsvMapping:  mapping,
+		Title:       h.Ctx.Conf.GetString("site.name"),
+		Description: h.Ctx.Conf.GetString("site.description"),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	name, contentType := exchange.FileName(format)
+	ctx.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	ctx.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+// SystemNewLink 管理接口：抓取网页信息并新建链接文档，请求体为 JSON，
+// 如 {"url": "https://example.com", "category": "tools", "dry_run": true}
+func (h *Handler) SystemNewLink(ctx *gin.Context) {
+
+	var opts service.NewLinkOptions
+	if err := ctx.ShouldBindJSON(&opts); err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	result, err := service.NewLink(h.Ctx, opts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: result},
+	})
+}
+
+// SystemBrokenLinks 管理接口：失效链接报告
+func (h *Handler) SystemBrokenLinks(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: service.GetBrokenLinks()},
+	})
+}
+
+// SystemBrokenWikiLinks 管理接口：正文中无法解析的 [[...]] 交叉链接
+func (h *Handler) SystemBrokenWikiLinks(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, Response{
+		Status:  0,
+		Message: "success",
+		Result:  Result{Data: service.GetBrokenWikiLinks()},
+	})
+}
+
+// SystemCheckLinks 管理接口：立即在后台检查一次全部链接
+func (h *Handler) SystemCheckLinks(ctx *gin.Context) {
+
+	if !h.Ctx.Conf.GetBool("linkcheck.enabled") {
+		ctx.JSON(http.StatusBadRequest, Response{Status: 1, Message: "未启用链接检查 linkcheck.enabled"})
+		return
+	}
+
+	if !service.TriggerLinkCheck() {
+		ctx.JSON(http.StatusConflict, Response{Status: 1, Message: "检查已在等待执行"})
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, Response{Status: 0, Message: "success"})
+}
@@ -13,12 +13,21 @@ import (
 func (h *Handler) Tag(ctx *gin.Context) {
 
 	params := ctx.Param("tagName")
+
+	query, err := parseQuery(ctx)
+	if err != nil {
+		ctx.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
 	data := service.GetTagDocuments(params, doc.SortByUpdateTime, doc.Descending)
 	if data == nil {
 		ctx.AbortWithStatus(http.StatusNotFound)
 		return
 	}
 
+	data = service.FilterCategoriesDocuments(data, query)
+
 	// result := Response{
 	// 	Status:  0,
 	// 	Message: "success",
@@ -32,13 +41,16 @@ func (h *Handler) Tag(ctx *gin.Context) {
 
 	result := Result{
 		Site:       service.GetSiteInfo(h.Ctx),
+		Menus:      service.GetMenus(),
 		Data:       data,
 		Tags:       service.GetAllTags(),
 		Tag:        params,
 		Categories: service.GetAllCategories(),
+		Query:      query.Raw,
+		LinkStatus: service.GetLinkStatuses(),
 	}
 
-	bytes, err := tpl.Render(h.TplDir, "tag.html", result)
+	bytes, err := tpl.Render("tag.html", result)
 	if err != nil {
 		h.Ctx.Log.Error(err.Error())
 		ctx.AbortWithError(http.StatusInternalServerError, err)
@@ -0,0 +1,473 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/bundle"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/pkg/wikilink"
+	"mdnav/internal/utils"
+)
+
+// Severity 问题级别
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// 检查规则
+const (
+	RuleFrontMatterError   = "front-matter-error"
+	RuleDataFileError      = "data-file-error"
+	RuleMissingFrontMatter = "missing-front-matter"
+	RuleMissingName        = "missing-name"
+	RuleMissingUrl         = "missing-url"
+	RuleInvalidUrl         = "invalid-url"
+	RuleDuplicateUrl       = "duplicate-url"
+	RuleMissingIndex       = "missing-index"
+	RuleEmptyCategory      = "empty-category"
+	RuleUnknownTag         = "unknown-tag"
+	RuleSlugCollision      = "slug-collision"
+	RuleInvalidSlug        = "invalid-slug"
+	RuleInvalidSort        = "invalid-sort"
+	RuleBrokenWikiLink     = "broken-wikilink"
+	RuleMissingAsset       = "missing-asset"
+)
+
+// Rules 所有规则及说明
+var Rules = map[string]string{
+	RuleFrontMatterError:   "front matter 无法解析（YAML/TOML/JSON）",
+	RuleDataFileError:      "链接数据文件无法解析（YAML/JSON/CSV）",
+	RuleMissingFrontMatter: "文件没有 front matter",
+	RuleMissingName:        "缺少 name",
+	RuleMissingUrl:         "文档缺少 url",
+	RuleInvalidUrl:         "url 格式错误",
+	RuleDuplicateUrl:       "多个文档使用相同的 url",
+	RuleMissingIndex:       "目录中有文档但缺少 _index.md",
+	RuleEmptyCategory:      "分类下没有任何文档",
+	RuleUnknownTag:         "标签不在 lint.tags 列表中",
+	RuleSlugCollision:      "slug 冲突或与保留路由重名",
+	RuleInvalidSlug:        "数据文件记录的 slug 不是单段的合法 slug（只能包含小写字母、数字和 -）",
+	RuleInvalidSort:        "sort_by 或 sort_order 无法识别",
+	RuleBrokenWikiLink:     "正文中的 [[...]] 引用找不到对应文档",
+	RuleMissingAsset:       "icon 或 image 的相对路径找不到文件或超出内容目录",
+}
+
+// ReservedSlugs 与站点路由冲突的一级分类名
+var ReservedSlugs = []string{"api", "article", "bundle", "collection", "icons", "latest", "preview", "static", "system", "tag", "updated"}
+
+// Issue 单个问题
+type Issue struct {
+	File     string   `json:"file"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// Report 检查结果
+type Report struct {
+	Files  int     `json:"files"`
+	Issues []Issue `json:"issues"`
+}
+
+// Options 检查选项
+type Options struct {
+	KnownTags []string // 允许的标签，为空时不检查
+}
+
+// HasErrors 是否存在错误，strict 时警告也算
+func (r *Report) HasErrors(strict bool) bool {
+	for _, v := range r.Issues {
+		if v.Severity == SeverityError || strict {
+			return true
+		}
+	}
+	return false
+}
+
+type entry struct {
+	file     string
+	slug     string
+	cateSlug string
+	isIndex  bool
+	meta     markdown.Markdown
+	keyLines map[string]int // front matter 键所在的行号
+	bodyLine int            // 正文第一行的行号
+	itemLine int            // 数据文件中记录所在的行号，md 文件为 0
+}
+
+// Run 按 cate.New 和 doc.New 相同的规则遍历内容目录并检查
+func Run(contentDir string, opts Options) (*Report, error) {
+
+	info, err := os.Stat(contentDir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s 不是目录", contentDir)
+	}
+
+	report := &Report{Issues: []Issue{}}
+	var entries []entry
+	dirsWithDocs := make(map[string]string) // cateSlug -> 第一个文档文件
+	links := wikilink.NewIndex()            // 交叉链接可以引用的文档，包括数据文件中的链接
+
+	err = filepath.WalkDir(contentDir, func(pathName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.IsDir() && markdown.IsDataFile(d.Name()) {
+			report.Files++
+			records := checkDataFile(report, contentDir, pathName)
+			if len(records) > 0 {
+				if _, ok := dirsWithDocs[records[0].cateSlug]; !ok {
+					dirsWithDocs[records[0].cateSlug] = pathName
+				}
+			}
+			entries = append(entries, records...)
+			return nil
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		report.Files++
+
+		rel, err := filepath.Rel(contentDir, pathName)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		e := entry{
+			file:     pathName,
+			cateSlug: path.Dir(rel),
+			isIndex:  d.Name() == "_index.md",
+		}
+		if e.cateSlug == "." {
+			e.cateSlug = ""
+		}
+		e.slug = strings.TrimSuffix(rel, ".md")
+		if e.isIndex {
+			e.slug = e.cateSlug
+		} else {
+			if _, ok := dirsWithDocs[e.cateSlug]; !ok {
+				dirsWithDocs[e.cateSlug] = pathName
+			}
+		}
+
+		if !checkFile(report, &e) {
+			return nil
+		}
+
+		entries = append(entries, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	checkEntries(report, entries, dirsWithDocs, opts)
+	checkWikiLinks(report, entries, links)
+	checkAssets(report, entries, contentDir)
+
+	sort.SliceStable(report.Issues, func(i, j int) bool {
+		if report.Issues[i].File == report.Issues[j].File {
+			return report.Issues[i].Line < report.Issues[j].Line
+		}
+		return report.Issues[i].File < report.Issues[j].File
+	})
+
+	return report, nil
+}
+
+// checkFile 解析单个文件并做字段检查，解析失败返回false
+func checkFile(report *Report, e *entry) bool {
+
+	content, err := os.ReadFile(e.file)
+	if err != nil {
+		report.add(e.file, 1, RuleFrontMatterError, SeverityError, err.Error())
+		return false
+	}
+
+	front, _, err := markdown.SplitFrontMatter(content)
+	if err == nil && front == nil {
+		report.add(e.file, 1, RuleMissingFrontMatter, SeverityWarning, Rules[RuleMissingFrontMatter])
+		return false
+	}
+
+	meta, keyLines, err := markdown.Parse(content)
+	if err != nil {
+		report.addParseError(e.file, RuleFrontMatterError, err)
+		return false
+	}
+
+	e.meta = meta
+	e.keyLines = keyLines
+	e.bodyLine = strings.Count(string(content), "\n") - strings.Count(meta.Markdown, "\n") + 1
+
+	if strings.TrimSpace(e.meta.Name) == "" {
+		report.add(e.file, 1, RuleMissingName, SeverityError, Rules[RuleMissingName])
+	}
+
+	if e.isIndex {
+		if e.meta.SortBy != "" {
+			if _, err := doc.ParseSortBy(e.meta.SortBy); err != nil {
+				report.add(e.file, e.line("sort_by"), RuleInvalidSort, SeverityWarning, err.Error()+"，将按更新时间排序")
+			}
+		}
+		if e.meta.SortOrder != "" {
+			if _, err := doc.ParseSortOrder(e.meta.SortOrder); err != nil {
+				report.add(e.file, e.line("sort_order"), RuleInvalidSort, SeverityWarning, err.Error())
+			}
+		}
+		return true
+	}
+
+	if strings.TrimSpace(e.meta.Url) == "" {
+		report.add(e.file, 1, RuleMissingUrl, SeverityError, Rules[RuleMissingUrl])
+	} else if msg := validateUrl(e.meta.Url); msg != "" {
+		report.add(e.file, e.line("url"), RuleInvalidUrl, SeverityError, msg)
+	}
+
+	return true
+}
+
+// checkEntries 跨文件检查：url重复、分类完整性、标签、slug冲突
+func checkEntries(report *Report, entries []entry, dirsWithDocs map[string]string, opts Options) {
+
+	knownTags := make(map[string]bool)
+	for _, t := range opts.KnownTags {
+		knownTags[strings.ToLower(t)] = true
+	}
+
+	reserved := make(map[string]bool)
+	for _, v := range ReservedSlugs {
+		reserved[v] = true
+	}
+
+	urls := make(map[string]string)          // 规范化url -> 文件
+	slugs := make(map[string]string)         // 小写slug -> 文件
+	frontSlugs := make(map[string]string)    // front matter 中声明的 slug -> 文件
+	categoryDocs := make(map[string]int)     // cateSlug -> 文档数
+	categoryFiles := make(map[string]string) // cateSlug -> _index.md
+
+	for _, e := range entries {
+		if e.isIndex {
+			categoryFiles[e.cateSlug] = e.file
+		} else {
+			categoryDocs[e.cateSlug]++
+		}
+	}
+
+	for _, e := range entries {
+
+		if !e.isIndex && e.meta.Url != "" && validateUrl(e.meta.Url) == "" {
+			key := utils.NormalizeUrl(e.meta.Url)
+			if first, ok := urls[key]; ok {
+				report.add(e.file, e.line("url"), RuleDuplicateUrl, SeverityError, fmt.Sprintf("url %s 与 %s 重复", e.meta.Url, first))
+			} else {
+				urls[key] = e.file
+			}
+		}
+
+		if len(knownTags) > 0 {
+			for _, t := range e.meta.Tags {
+				if !knownTags[strings.ToLower(t)] {
+					report.add(e.file, e.line("tags"), RuleUnknownTag, SeverityWarning, fmt.Sprintf("未知标签 %q", t))
+				}
+			}
+		}
+
+		key := strings.ToLower(e.slug)
+		if first, ok := slugs[key]; ok {
+			report.add(e.file, e.line(""), RuleSlugCollision, SeverityError, fmt.Sprintf("slug %q 与 %s 冲突", e.slug, first))
+		} else {
+			slugs[key] = e.file
+		}
+
+		if e.meta.Slug != "" {
+			if first, ok := frontSlugs[e.meta.Slug]; ok {
+				report.add(e.file, e.line("slug"), RuleSlugCollision, SeverityError, fmt.Sprintf("slug %q 与 %s 重复", e.meta.Slug, first))
+			} else {
+				frontSlugs[e.meta.Slug] = e.file
+			}
+		}
+
+		if e.isIndex {
+			top := strings.SplitN(e.cateSlug, "/", 2)[0]
+			if reserved[strings.ToLower(top)] {
+				report.add(e.file, 1, RuleSlugCollision, SeverityError, fmt.Sprintf("分类 %q 与保留路由 /%s 重名", e.cateSlug, top))
+			}
+			if categoryDocs[e.cateSlug] == 0 {
+				report.add(e.file, 1, RuleEmptyCategory, SeverityWarning, Rules[RuleEmptyCategory])
+			}
+		}
+	}
+
+	for cateSlug, file := range dirsWithDocs {
+		if _, ok := categoryFiles[cateSlug]; !ok {
+			report.add(file, 1, RuleMissingIndex, SeverityWarning, fmt.Sprintf("目录 %q 缺少 _index.md，其中的文档不会显示", cateSlug))
+		}
+	}
+}
+
+// checkWikiLinks 检查文档正文中的交叉链接能否找到对应文档，规则与站点加载时相同
+func checkWikiLinks(report *Report, entries []entry, links *wikilink.Index) {
+
+	for _, e := range entries {
+		if !e.isIndex {
+			links.Add(e.slug, e.meta.Name)
+		}
+	}
+
+	for _, e := range entries {
+		if e.isIndex {
+			continue
+		}
+		for _, ref := range wikilink.Extract(e.meta.Markdown) {
+			if _, err := links.Resolve(ref.Target); err != nil {
+				report.add(e.file, e.bodyLine+ref.Line-1, RuleBrokenWikiLink, SeverityWarning, fmt.Sprintf("[[%s]] %v", ref.Target, err))
+			}
+		}
+	}
+}
+
+// checkAssets 检查 icon、image 中的相对路径，规则与站点加载时相同
+func checkAssets(report *Report, entries []entry, contentDir string) {
+
+	for _, e := range entries {
+		for _, field := range [][2]string{{"icon", e.meta.Icon}, {"image", e.meta.Image}} {
+			key, ref := field[0], field[1]
+			if !bundle.IsRelative(ref) {
+				continue
+			}
+			resolved := bundle.URL(e.cateSlug, ref)
+			if resolved == ref {
+				report.add(e.file, e.line(key), RuleMissingAsset, SeverityWarning, fmt.Sprintf("%s %s 超出内容目录", key, ref))
+				continue
+			}
+			u, err := url.Parse(resolved)
+			if err != nil {
+				continue
+			}
+			file, _, err := bundle.Open(contentDir, strings.TrimPrefix(u.Path, bundle.Prefix))
+			if err != nil {
+				report.add(e.file, e.line(key), RuleMissingAsset, SeverityWarning, fmt.Sprintf("%s %s 文件不存在", key, ref))
+				continue
+			}
+			file.Close()
+		}
+	}
+}
+
+// checkDataFile 解析链接数据文件，每条记录按与 md 文档相同的规则检查，slug 规则与 doc.New 相同
+func checkDataFile(report *Report, contentDir, pathName string) []entry {
+
+	records, err := markdown.ParseDataFile(pathName)
+	if errors.Is(err, markdown.ErrInvalidRecordSlug) {
+		report.addParseError(pathName, RuleInvalidSlug, err)
+		return nil
+	}
+	if err != nil {
+		report.addParseError(pathName, RuleDataFileError, err)
+		return nil
+	}
+
+	rel, err := filepath.Rel(contentDir, filepath.Dir(pathName))
+	if err != nil {
+		return nil
+	}
+	cateSlug := filepath.ToSlash(rel)
+	if cateSlug == "." {
+		cateSlug = ""
+	}
+
+	var entries []entry
+	for _, record := range records {
+
+		e := entry{
+			file:     pathName,
+			slug:     path.Join(cateSlug, doc.RecordSlug(record)),
+			cateSlug: cateSlug,
+			meta:     record.Markdown(),
+			itemLine: record.Line,
+		}
+		e.meta.Slug = "" // 记录的 slug 已包含在 e.slug 中，不参与 front matter slug 的重复检查
+
+		if strings.TrimSpace(record.Name) == "" {
+			report.add(e.file, e.line("name"), RuleMissingName, SeverityError, Rules[RuleMissingName])
+		}
+
+		if strings.TrimSpace(record.Url) == "" {
+			report.add(e.file, e.line("url"), RuleMissingUrl, SeverityError, Rules[RuleMissingUrl])
+		} else if msg := validateUrl(record.Url); msg != "" {
+			report.add(e.file, e.line("url"), RuleInvalidUrl, SeverityError, msg)
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+func (r *Report) add(file string, line int, rule string, severity Severity, message string) {
+	if line < 1 {
+		line = 1
+	}
+	r.Issues = append(r.Issues, Issue{File: file, Line: line, Column: 1, Rule: rule, Severity: severity, Message: message})
+}
+
+// addParseError 记录解析错误，带行号时使用出错的位置
+func (r *Report) addParseError(file, rule string, err error) {
+	line, column := 1, 1
+	var parseErr *markdown.ParseError
+	if errors.As(err, &parseErr) {
+		line, column = parseErr.Line, parseErr.Column
+		err = parseErr.Err
+	}
+	r.add(file, line, rule, SeverityError, err.Error())
+	if column > 1 {
+		r.Issues[len(r.Issues)-1].Column = column
+	}
+}
+
+// line 字段所在的行号，数据文件中的记录使用记录所在的行号
+func (e entry) line(key string) int {
+	if line, ok := e.keyLines[key]; ok {
+		return line
+	}
+	if e.itemLine > 0 {
+		return e.itemLine
+	}
+	return 1
+}
+
+func validateUrl(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return fmt.Sprintf("url %q 格式错误: %v", raw, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Sprintf("url %q 必须以 http:// 或 https:// 开头", raw)
+	}
+	if u.Host == "" {
+		return fmt.Sprintf("url %q 缺少域名", raw)
+	}
+	return ""
+}
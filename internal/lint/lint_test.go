@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestRunDataFiles(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  []string // 期望的问题，格式为 文件:行号:规则
+	}{
+		{
+			name: "valid records",
+			files: map[string]string{
+				"tools/_index.md":    "---\nname: Tools\n---\n",
+				"tools/links.yaml":   "- name: A\n  url: https://a.com\n- name: B\n  url: https://b.com\n",
+				"tools/c.md":         "---\nname: C\nurl: https://c.com\n---\n[[A]]\n",
+				"other/_index.md":    "---\nname: Other\n---\n",
+				"other/links.json":   `[{"name": "D", "url": "https://d.com"}]`,
+				"other/links.csv":    "name,url\nE,https://e.com\n",
+				"other/e2/_index.md": "---\nname: E2\n---\n",
+				"other/e2/x.md":      "---\nname: X\nurl: https://x.com\n---\n",
+			},
+		},
+		{
+			name: "parse error",
+			files: map[string]string{
+				"tools/_index.md":  "---\nname: Tools\n---\n",
+				"tools/a.md":       "---\nname: A\nurl: https://a.com\n---\n",
+				"tools/links.yaml": "- name: A\n  url: https://b.com\n  publish_at: soon\n",
+			},
+			want: []string{"tools/links.yaml:3:data-file-error"},
+		},
+		{
+			name: "record checks",
+			files: map[string]string{
+				"tools/_index.md": "---\nname: Tools\n---\n",
+				"tools/links.yaml": "- url: https://a.com\n" +
+					"- name: B\n  slug: b\n" +
+					"- name: C\n  url: ftp://c.com\n",
+				"tools/links.json": "[\n  {\"name\": \"D\"}\n]",
+				"tools/links.csv":  "name,url\n,https://e.com\n",
+			},
+			want: []string{
+				"tools/links.csv:2:missing-name",
+				"tools/links.json:2:missing-url",
+				"tools/links.yaml:1:missing-name",
+				"tools/links.yaml:2:missing-url",
+				"tools/links.yaml:4:invalid-url",
+			},
+		},
+		{
+			name: "duplicate url and slug with md",
+			files: map[string]string{
+				"tools/_index.md":  "---\nname: Tools\n---\n",
+				"tools/a.md":       "---\nname: A\nurl: https://a.com\n---\n",
+				"tools/links.yaml": "- name: A2\n  url: https://www.a.com/\n- name: B\n  slug: a\n  url: https://b.com\n",
+			},
+			want: []string{
+				"tools/links.yaml:1:duplicate-url",
+				"tools/links.yaml:3:slug-collision",
+			},
+		},
+		{
+			name: "invalid record slug",
+			files: map[string]string{
+				"tools/_index.md":  "---\nname: Tools\n---\n",
+				"tools/a.md":       "---\nname: A\nurl: https://a.com\n---\n",
+				"tools/links.yaml": "- name: B\n  url: https://b.com\n- name: C\n  slug: ../other/c\n  url: https://c.com\n",
+			},
+			want: []string{"tools/links.yaml:3:invalid-slug"},
+		},
+		{
+			name: "records without index",
+			files: map[string]string{
+				"tools/links.yaml": "- name: A\n  url: https://a.com\n",
+			},
+			want: []string{"tools/links.yaml:1:missing-index"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				file := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report, err := Run(dir, Options{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, v := range report.Issues {
+				rel, _ := filepath.Rel(dir, v.File)
+				got = append(got, filepath.ToSlash(rel)+":"+strconv.Itoa(v.Line)+":"+v.Rule)
+			}
+			sort.Strings(got)
+
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("issues:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+)
+
+// 输出格式
+const (
+	FormatText  = "text"
+	FormatJson  = "json"
+	FormatSarif = "sarif"
+)
+
+// Write 按指定格式输出检查结果
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatText, "":
+		return r.writeText(w)
+	case FormatJson:
+		return writeJson(w, r)
+	case FormatSarif:
+		return writeJson(w, r.sarif())
+	default:
+		return fmt.Errorf("不支持的输出格式 %q，可选 text、json、sarif", format)
+	}
+}
+
+func (r *Report) writeText(w io.Writer) error {
+
+	errors, warnings := 0, 0
+	for _, v := range r.Issues {
+		if v.Severity == SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s [%s] %s\n", v.File, v.Line, v.Column, v.Severity, v.Rule, v.Message); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "检查 %d 个文件，%d 个错误，%d 个警告\n", r.Files, errors, warnings)
+	return err
+}
+
+func writeJson(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// SARIF 2.1.0 最小结构，供代码托管平台展示检查结果
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	Id               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleId    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	Uri string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+}
+
+func (r *Report) sarif() sarifLog {
+
+	var ruleIds []string
+	for id := range Rules {
+		ruleIds = append(ruleIds, id)
+	}
+	sort.Strings(ruleIds)
+
+	driver := sarifDriver{Name: "mdnav-lint"}
+	for _, id := range ruleIds {
+		driver.Rules = append(driver.Rules, sarifRule{Id: id, ShortDescription: sarifMessage{Text: Rules[id]}})
+	}
+
+	results := []sarifResult{}
+	for _, v := range r.Issues {
+		results = append(results, sarifResult{
+			RuleId:  v.Rule,
+			Level:   string(v.Severity),
+			Message: sarifMessage{Text: v.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifact{Uri: filepath.ToSlash(v.File)},
+					Region:           sarifRegion{StartLine: v.Line, StartColumn: v.Column},
+				},
+			}},
+		})
+	}
+
+	return sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: driver}, Results: results}},
+	}
+}
@@ -1,7 +1,6 @@
 package middleware
 
 import (
-	"mdnav/internal/conf"
 	"mdnav/internal/core"
 	"mdnav/internal/utils/tpl"
 	"net/http"
@@ -15,8 +14,9 @@ func RequestError(ctx *core.Context) gin.HandlerFunc {
 
 		c.Next()
 
+		// 已经输出了响应体（如 JSON 接口的错误信息）时不再渲染错误页
 		status := c.Writer.Status()
-		if status >= 400 {
+		if status >= 400 && c.Writer.Size() <= 0 {
 
 			eMsg := http.StatusText(status)
 
@@ -28,8 +28,7 @@ func RequestError(ctx *core.Context) gin.HandlerFunc {
 				Msg:  eMsg,
 			}
 
-			tplDir := conf.Get().GetString("template.dir")
-			bytes, err := tpl.Render(tplDir, "error.html", httpError)
+			bytes, err := tpl.Render("error.html", httpError)
 			if err != nil {
 				ctx.Log.Error(err.Error())
 				c.AbortWithStatus(500)
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"mdnav/internal/core"
+	"mdnav/internal/pkg/bundle"
 	"mdnav/internal/pkg/markdown"
 )
 
@@ -30,6 +31,15 @@ type Category struct {
 	DocumentCount int       `json:"document_count"`
 	CreateTIme    time.Time `json:"create_time"`
 	UpdateTIme    time.Time `json:"update_time"`
+	Layout        string    `json:"layout"`     // 分类页模板变体
+	Template      string    `json:"template"`   // 分类页模板文件
+	SortBy        string    `json:"sort_by"`    // 文档默认排序字段
+	SortOrder     string    `json:"sort_order"` // 文档默认排序顺序
+}
+
+// IsVisible 分类是否对访客可见
+func (c Category) IsVisible() bool {
+	return c.Published && c.IsShow
 }
 
 type CategoriesMap struct {
@@ -86,6 +96,25 @@ func (c *CategoriesMap) GetCategoriesBySlug(slug string) *Category {
 	return nil
 }
 
+// Filter 返回只包含满足条件分类的新 CategoriesMap
+func (c *CategoriesMap) Filter(keep func(Category) bool) *CategoriesMap {
+
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	filtered := &CategoriesMap{
+		categories: make(map[string]Category),
+	}
+
+	for slug, cate := range c.categories {
+		if keep(cate) {
+			filtered.categories[slug] = cate
+		}
+	}
+
+	return filtered
+}
+
 func getAllCategories(ctx *core.Context) (map[string]Category, error) {
 
 	categories := make(map[string]Category)
@@ -126,21 +155,26 @@ func getAllCategories(ctx *core.Context) (map[string]Category, error) {
 				return nil // 继续处理其他文件
 			}
 
+			// icon、image 为相对路径时指向分类目录中的资源
 			cateSlug := strings.TrimPrefix(path.Dir(pathName), walkDir)
 			categories[cateSlug] = Category{
 				Name:        mdCont.Name,
 				Keywords:    mdCont.Keywords,
 				Description: mdCont.Description,
 				Slug:        cateSlug,
-				Icon:        mdCont.Icon,
+				Icon:        bundle.URL(cateSlug, mdCont.Icon),
 				Markdown:    mdCont.Markdown,
-				Image:       mdCont.Image,
+				Image:       bundle.URL(cateSlug, mdCont.Image),
 				Sort:        mdCont.Sort,
 				Custom:      mdCont.Custom,
 				Published:   mdCont.Published,
 				CreateTIme:  mdCont.CreateTime,
 				UpdateTIme:  mdCont.UpdateTime,
 				IsShow:      mdCont.IsShow,
+				Layout:      mdCont.Layout,
+				Template:    mdCont.Template,
+				SortBy:      mdCont.SortBy,
+				SortOrder:   mdCont.SortOrder,
 			}
 		}
 
@@ -0,0 +1,180 @@
+package collection
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/pkg/zap"
+)
+
+// Collection 合集，由一组按顺序列出的文档或一个保存的过滤表达式组成
+type Collection struct {
+	Name        string    `json:"name"`
+	Keywords    string    `json:"keywords"`
+	Description string    `json:"description"`
+	Slug        string    `json:"slug"`
+	Icon        string    `json:"icon"`
+	Image       string    `json:"image"`
+	Markdown    string    `json:"markdown"` // 合集介绍
+	Sort        int       `json:"sort"`
+	Items       []string  `json:"items"` // 文档slug，按书写顺序展示
+	Query       string    `json:"query"` // 过滤表达式，结果追加在 Items 之后
+	Published   bool      `json:"published"`
+	Custom      any       `json:"custom"`
+	CreateTime  time.Time `json:"create_time"`
+	UpdateTime  time.Time `json:"update_time"`
+	query       *doc.Query
+
+	markdown.Rendered // 加载时预先渲染的合集介绍
+}
+
+// GetQuery 获取解析后的过滤表达式，未设置时返回nil
+func (c Collection) GetQuery() *doc.Query {
+	return c.query
+}
+
+type CollectionsMap struct {
+	collections map[string]Collection
+	mx          sync.RWMutex
+}
+
+func New(ctx *core.Context) (*CollectionsMap, error) {
+
+	collectionsMap := &CollectionsMap{
+		collections: make(map[string]Collection),
+	}
+
+	collections, err := getAllCollections(ctx)
+	if err != nil {
+		return nil, err
+	}
+	collectionsMap.collections = collections
+
+	return collectionsMap, nil
+}
+
+// GetCollectionsSlice 获取按排序权重升序的合集数组
+func (c *CollectionsMap) GetCollectionsSlice() []Collection {
+
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	var collections []Collection
+	for _, v := range c.collections {
+		collections = append(collections, v)
+	}
+
+	sort.SliceStable(collections, func(i, j int) bool {
+		if collections[i].Sort == collections[j].Sort {
+			return collections[i].Slug < collections[j].Slug
+		}
+		return collections[i].Sort < collections[j].Sort
+	})
+
+	return collections
+}
+
+// GetCollectionBySlug 根据slug获取合集
+func (c *CollectionsMap) GetCollectionBySlug(slug string) *Collection {
+
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+
+	collection, ok := c.collections[slug]
+	if ok {
+		return &collection
+	}
+
+	return nil
+}
+
+func getAllCollections(ctx *core.Context) (map[string]Collection, error) {
+
+	collections := make(map[string]Collection)
+
+	dirPath := ctx.Conf.GetString("server.collections_dir")
+	if dirPath == "" {
+		dirPath = "./collections/"
+	}
+
+	// 合集目录是可选的
+	info, err := os.Stat(dirPath)
+	if os.IsNotExist(err) {
+		return collections, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return collections, nil
+	}
+
+	err = filepath.WalkDir(dirPath, func(pathName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			ctx.Log.Error(err.Error())
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		mdCont, err := markdown.Parser(pathName)
+		if err != nil {
+			ctx.Log.Error(err.Error())
+			return nil // 继续处理其他文件
+		}
+
+		rel, err := filepath.Rel(dirPath, pathName)
+		if err != nil {
+			return nil
+		}
+		slug := strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+
+		collection := Collection{
+			Name:        mdCont.Name,
+			Keywords:    mdCont.Keywords,
+			Description: mdCont.Description,
+			Slug:        slug,
+			Icon:        mdCont.Icon,
+			Image:       mdCont.Image,
+			Markdown:    mdCont.Markdown,
+			Sort:        mdCont.Sort,
+			Items:       mdCont.Items,
+			Query:       mdCont.Query,
+			Published:   mdCont.Published,
+			Custom:      mdCont.Custom,
+			CreateTime:  mdCont.CreateTime,
+			UpdateTime:  mdCont.UpdateTime,
+		}
+
+		collection.Rendered = markdown.Render(collection.Markdown)
+
+		if collection.Query != "" {
+			q, err := doc.ParseQuery(collection.Query)
+			if err != nil {
+				ctx.Log.Error("合集过滤表达式错误", zap.String("file", pathName), zap.Error(err))
+				return nil
+			}
+			collection.query = q
+		}
+
+		collections[slug] = collection
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
@@ -12,7 +12,9 @@ import (
 	"time"
 
 	"mdnav/internal/core"
+	"mdnav/internal/pkg/bundle"
 	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/utils"
 )
 
 type Document struct {
@@ -32,6 +34,41 @@ type Document struct {
 	Custom      any       `json:"custom"`      // 自定义数据
 	UpdateTime  time.Time `json:"update_time"` // 修改时间，自动从文件属性获取
 	Markdown    string    `json:"markdown"`    // Markdown原始内容
+	Featured    bool      `json:"featured"`    // 是否在首页推荐
+	PinUntil    time.Time `json:"pin_until"`   // 推荐截止时间，为空表示一直推荐
+	PublishAt   time.Time `json:"publish_at"`  // 定时发布时间，为空表示立即发布
+	ExpireAt    time.Time `json:"expire_at"`   // 过期时间，为空表示永不过期
+	Source      string    `json:"source"`      // 来源文件路径，md 文件或链接数据文件
+	Layout      string    `json:"layout"`      // 文档页模板变体
+	Template    string    `json:"template"`    // 文档页模板文件
+
+	markdown.Rendered // 加载时预先渲染的正文、目录和阅读时间
+}
+
+// IsDraft 是否为草稿或被隐藏
+func (d Document) IsDraft() bool {
+	return !d.Published || !d.IsShow
+}
+
+// IsVisible 判断文档在指定时间是否对访客可见
+func (d Document) IsVisible(now time.Time) bool {
+	return !d.IsDraft() && d.IsAvailable(now)
+}
+
+// IsAvailable 判断文档在指定时间是否处于发布窗口内
+func (d Document) IsAvailable(now time.Time) bool {
+	if !d.PublishAt.IsZero() && now.Before(d.PublishAt) {
+		return false
+	}
+	if !d.ExpireAt.IsZero() && !now.Before(d.ExpireAt) {
+		return false
+	}
+	return true
+}
+
+// IsFeatured 判断文档在指定时间是否处于推荐状态
+func (d Document) IsFeatured(now time.Time) bool {
+	return d.Featured && (d.PinUntil.IsZero() || now.Before(d.PinUntil))
 }
 
 type DocumentsMap struct {
@@ -112,6 +149,74 @@ func (d *DocumentsMap) GetDocumentsSlugByTag(tagName string) []string {
 	return nil
 }
 
+// Filter 返回只包含满足条件文档的新 DocumentsMap，标签索引同步重建
+func (d *DocumentsMap) Filter(keep func(Document) bool) *DocumentsMap {
+
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+
+	filtered := &DocumentsMap{
+		documents: make(map[string]Document),
+		tags:      make(map[string][]string),
+	}
+
+	for slug, doc := range d.documents {
+		if !keep(doc) {
+			continue
+		}
+		filtered.documents[slug] = doc
+		for _, v := range doc.Tags {
+			filtered.tags[v] = append(filtered.tags[v], slug)
+		}
+	}
+
+	for _, slugs := range filtered.tags {
+		sort.Strings(slugs)
+	}
+
+	return filtered
+}
+
+// WithDocuments 返回使用 docs 替换同 slug 文档后的新 DocumentsMap，用于写入预先渲染的正文
+func (d *DocumentsMap) WithDocuments(docs []Document) *DocumentsMap {
+
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+
+	replaced := &DocumentsMap{
+		documents: make(map[string]Document, len(d.documents)),
+		tags:      d.tags,
+	}
+	for slug, doc := range d.documents {
+		replaced.documents[slug] = doc
+	}
+	for _, doc := range docs {
+		if _, ok := replaced.documents[doc.Slug]; ok {
+			replaced.documents[doc.Slug] = doc
+		}
+	}
+
+	return replaced
+}
+
+// NextBoundary 获取 now 之后最近的一个发布或过期时间点，没有时返回零值
+func (d *DocumentsMap) NextBoundary(now time.Time) time.Time {
+
+	d.mx.RLock()
+	defer d.mx.RUnlock()
+
+	var next time.Time
+	for _, doc := range d.documents {
+		for _, t := range []time.Time{doc.PublishAt, doc.ExpireAt} {
+			if t.After(now) && (next.IsZero() || t.Before(next)) {
+				next = t
+			}
+		}
+	}
+
+	return next
+}
+
 func getAllDocuments(ctx *core.Context) (map[string]Document, map[string][]string, error) {
 
 	documents := make(map[string]Document)
@@ -130,6 +235,9 @@ func getAllDocuments(ctx *core.Context) (map[string]Document, map[string][]strin
 
 	walkDir := strings.TrimRight(info.Name(), "/") + "/"
 
+	// 数据文件中的记录在全部 md 文件之后加入，slug 冲突时以 md 文件为准
+	var recordDocs []Document
+
 	// 遍历目录
 	err = filepath.WalkDir(walkDir, func(pathName string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -141,6 +249,33 @@ func getAllDocuments(ctx *core.Context) (map[string]Document, map[string][]strin
 			return nil
 		}
 
+		cateSlug := strings.TrimPrefix(path.Dir(pathName), walkDir)
+
+		// 链接数据文件，每条记录对应一个文档
+		if markdown.IsDataFile(d.Name()) {
+			fileRecords, err := markdown.ParseDataFile(pathName)
+			if err != nil {
+				ctx.Log.Error(err.Error())
+				return nil // 继续处理其他文件
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				ctx.Log.Error(err.Error())
+				return nil
+			}
+
+			for _, record := range fileRecords {
+				mdCont := record.Markdown()
+				mdCont.UpdateTime = info.ModTime()
+
+				slug := path.Join(cateSlug, RecordSlug(record))
+				recordDocs = append(recordDocs, newDocument(mdCont, slug, cateSlug, pathName))
+			}
+
+			return nil
+		}
+
 		if !strings.HasSuffix(d.Name(), ".md") {
 			return nil
 		}
@@ -155,33 +290,8 @@ func getAllDocuments(ctx *core.Context) (map[string]Document, map[string][]strin
 			return nil // 继续处理其他文件
 		}
 
-		cateSlug := strings.TrimPrefix(path.Dir(pathName), walkDir)
 		slug := strings.TrimSuffix(path.Join(cateSlug, d.Name()), ".md")
-		sort.Strings(mdCont.Tags)
-		document := Document{
-			Name:        mdCont.Name,
-			Keywords:    mdCont.Keywords,
-			Description: mdCont.Description,
-			Published:   mdCont.Published,
-			IsShow:      mdCont.IsShow,
-			Sort:        mdCont.Sort,
-			Icon:        mdCont.Icon,
-			Url:         mdCont.Url,
-			Slug:        slug,
-			CateSlug:    cateSlug,
-			Tags:        mdCont.Tags,
-			Image:       mdCont.Image,
-			CreateTime:  mdCont.CreateTime,
-			Custom:      mdCont.Custom,
-			UpdateTime:  mdCont.UpdateTime,
-			Markdown:    mdCont.Markdown,
-		}
-
-		for _, v := range document.Tags {
-			tags[v] = append(tags[v], document.Slug)
-		}
-
-		documents[slug] = document
+		addDocument(documents, tags, newDocument(mdCont, slug, cateSlug, pathName))
 
 		return nil
 	})
@@ -190,5 +300,62 @@ func getAllDocuments(ctx *core.Context) (map[string]Document, map[string][]strin
 		return nil, nil, err
 	}
 
+	for _, record := range recordDocs {
+		if first, ok := documents[record.Slug]; ok {
+			ctx.Log.Error("文档slug重复：" + record.Slug + "，文件：" + record.Source + "，与 " + first.Source + " 冲突，已忽略")
+			continue
+		}
+		addDocument(documents, tags, record)
+	}
+
 	return documents, tags, nil
 }
+
+// RecordSlug 数据文件记录的slug，未指定时根据url生成，url不变则slug不变
+func RecordSlug(record markdown.LinkRecord) string {
+	if record.Slug != "" {
+		return record.Slug
+	}
+	return "link-" + utils.GenerateShortCode(strings.TrimSpace(record.Url))
+}
+
+// newDocument 创建文档，icon、image 为相对路径时指向文档所在目录中的资源
+func newDocument(mdCont markdown.Markdown, slug, cateSlug, source string) Document {
+
+	sort.Strings(mdCont.Tags)
+
+	return Document{
+		Name:        mdCont.Name,
+		Keywords:    mdCont.Keywords,
+		Description: mdCont.Description,
+		Published:   mdCont.Published,
+		IsShow:      mdCont.IsShow,
+		Sort:        mdCont.Sort,
+		Icon:        bundle.URL(cateSlug, mdCont.Icon),
+		Url:         mdCont.Url,
+		Slug:        slug,
+		CateSlug:    cateSlug,
+		Tags:        mdCont.Tags,
+		Image:       bundle.URL(cateSlug, mdCont.Image),
+		CreateTime:  mdCont.CreateTime,
+		Custom:      mdCont.Custom,
+		UpdateTime:  mdCont.UpdateTime,
+		Markdown:    mdCont.Markdown,
+		Featured:    mdCont.Featured,
+		PinUntil:    mdCont.PinUntil,
+		PublishAt:   mdCont.PublishAt,
+		ExpireAt:    mdCont.ExpireAt,
+		Source:      source,
+		Layout:      mdCont.Layout,
+		Template:    mdCont.Template,
+	}
+}
+
+func addDocument(documents map[string]Document, tags map[string][]string, document Document) {
+
+	for _, v := range document.Tags {
+		tags[v] = append(tags[v], document.Slug)
+	}
+
+	documents[document.Slug] = document
+}
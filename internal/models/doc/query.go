@@ -0,0 +1,277 @@
+package doc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query 文档过滤表达式，例如：
+//
+//	category:ai-tools tag:image updated:>2025-01-01 sort:-sort
+//
+// 多个条件之间为"与"关系，字段前加 "-" 表示取反（如 -tag:image），
+// 不带字段名的词在名称、描述和关键词中做包含匹配。
+type Query struct {
+	Raw     string    `json:"raw"`
+	SortBy  SortBy    `json:"sort_by"`
+	Order   SortOrder `json:"order"`
+	Limit   int       `json:"limit"`
+	filters []queryFilter
+}
+
+type queryFilter struct {
+	field  string
+	op     string
+	value  string
+	negate bool
+	start  time.Time // 日期条件所表示时间段的开始
+	end    time.Time // 日期条件所表示时间段的结束（不含），如 2025-06 为 2025-07-01
+	number int
+}
+
+// 支持的日期格式及其精度，日期表示从该时间开始、长度为精度的时间段
+var queryTimeLayouts = []struct {
+	layout string
+	next   func(time.Time) time.Time
+}{
+	{time.RFC3339, func(t time.Time) time.Time { return t.Add(time.Second) }},
+	{"2006-01-02 15:04:05", func(t time.Time) time.Time { return t.Add(time.Second) }},
+	{"2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }},
+	{"2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }},
+	{"2006", func(t time.Time) time.Time { return t.AddDate(1, 0, 0) }},
+}
+
+// ParseQuery 解析过滤表达式
+func ParseQuery(raw string) (*Query, error) {
+
+	q := &Query{Raw: strings.TrimSpace(raw)}
+
+	tokens, err := splitQuery(q.Raw)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+
+		negate := false
+		if strings.HasPrefix(token, "-") && len(token) > 1 {
+			negate = true
+			token = token[1:]
+		}
+
+		// 引号开头的是全文短语，其中的冒号不作为字段分隔符
+		field, value, ok := strings.Cut(token, ":")
+		if !ok || strings.HasPrefix(token, `"`) {
+			if text := strings.ToLower(strings.Trim(token, `"`)); text != "" {
+				q.filters = append(q.filters, queryFilter{field: "text", op: "=", value: text, negate: negate})
+			}
+			continue
+		}
+
+		field = strings.ToLower(field)
+		value = strings.Trim(value, `"`)
+		if value == "" {
+			return nil, fmt.Errorf("查询条件 %q 缺少值", token)
+		}
+
+		switch field {
+		case "sort":
+			if negate {
+				return nil, fmt.Errorf("排序条件 %q 不能取反", token)
+			}
+			q.Order = Ascending
+			if strings.HasPrefix(value, "-") {
+				q.Order = Descending
+				value = value[1:]
+			}
+			sortBy, err := ParseSortBy(value)
+			if err != nil {
+				return nil, err
+			}
+			q.SortBy = sortBy
+		case "limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("limit 必须为非负整数: %q", value)
+			}
+			q.Limit = n
+		case "category", "cate", "tag", "name", "url", "slug":
+			if field == "cate" {
+				field = "category"
+			}
+			q.filters = append(q.filters, queryFilter{field: field, op: "=", value: value, negate: negate})
+		case "created", "updated":
+			op, v := splitOperator(value)
+			start, end, err := parseQueryTime(v)
+			if err != nil {
+				return nil, fmt.Errorf("查询条件 %q 日期格式错误", token)
+			}
+			q.filters = append(q.filters, queryFilter{field: field, op: op, value: v, negate: negate, start: start, end: end})
+		case "weight":
+			op, v := splitOperator(value)
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("查询条件 %q 必须为整数", token)
+			}
+			q.filters = append(q.filters, queryFilter{field: field, op: op, value: v, negate: negate, number: n})
+		default:
+			return nil, fmt.Errorf("不支持的查询字段 %q", field)
+		}
+	}
+
+	return q, nil
+}
+
+// Match 判断文档是否满足所有过滤条件
+func (q *Query) Match(d Document) bool {
+
+	for _, f := range q.filters {
+		if f.match(d) == f.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Apply 过滤并排序文档，未指定排序时保持原顺序
+func (q *Query) Apply(docs []Document) []Document {
+
+	var result []Document
+	for _, d := range docs {
+		if q.Match(d) {
+			result = append(result, d)
+		}
+	}
+
+	if q.SortBy != "" {
+		result = SortDocuments(result, q.SortBy, q.Order)
+	}
+
+	if q.Limit > 0 && len(result) > q.Limit {
+		result = result[:q.Limit]
+	}
+
+	return result
+}
+
+// IsEmpty 是否为空查询
+func (q *Query) IsEmpty() bool {
+	return q == nil || (len(q.filters) == 0 && q.SortBy == "" && q.Limit == 0)
+}
+
+func (f queryFilter) match(d Document) bool {
+	switch f.field {
+	case "category":
+		return d.CateSlug == f.value
+	case "slug":
+		return d.Slug == f.value
+	case "tag":
+		for _, t := range d.Tags {
+			if strings.EqualFold(t, f.value) {
+				return true
+			}
+		}
+		return false
+	case "name":
+		return strings.Contains(strings.ToLower(d.Name), strings.ToLower(f.value))
+	case "url":
+		return strings.Contains(strings.ToLower(d.Url), strings.ToLower(f.value))
+	case "created":
+		return compareTime(d.CreateTime, f.op, f.start, f.end)
+	case "updated":
+		return compareTime(d.UpdateTime, f.op, f.start, f.end)
+	case "weight":
+		return compareInt(d.Sort, f.op, f.number)
+	case "text":
+		return strings.Contains(strings.ToLower(d.Name), f.value) ||
+			strings.Contains(strings.ToLower(d.Description), f.value) ||
+			strings.Contains(strings.ToLower(d.Keywords), f.value)
+	}
+	return false
+}
+
+// splitQuery 按空白切分表达式，双引号内的空白保留，引号未闭合时返回错误
+func splitQuery(raw string) ([]string, error) {
+
+	var tokens []string
+	var buf strings.Builder
+	quoted := false
+
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			buf.WriteRune(r)
+		case (r == ' ' || r == '\t' || r == '\n') && !quoted:
+			if buf.Len() > 0 {
+				tokens = append(tokens, buf.String())
+				buf.Reset()
+			}
+		default:
+			buf.WriteRune(r)
+		}
+	}
+
+	if quoted {
+		return nil, fmt.Errorf("查询表达式 %q 中的引号未闭合", raw)
+	}
+
+	if buf.Len() > 0 {
+		tokens = append(tokens, buf.String())
+	}
+
+	return tokens, nil
+}
+
+func splitOperator(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+// parseQueryTime 解析日期，返回按日期精度表示的时间段 [start, end)，如 2025 为整年
+func parseQueryTime(value string) (start, end time.Time, err error) {
+	for _, v := range queryTimeLayouts {
+		if t, err := time.ParseInLocation(v.layout, value, time.Local); err == nil {
+			return t, v.next(t), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("无法解析日期 %q", value)
+}
+
+// compareTime 按时间段比较：= 表示在时间段内，> 表示在时间段之后，>= 表示不早于时间段的开始，以此类推
+func compareTime(t time.Time, op string, start, end time.Time) bool {
+	switch op {
+	case ">":
+		return !t.Before(end)
+	case ">=":
+		return !t.Before(start)
+	case "<":
+		return t.Before(start)
+	case "<=":
+		return t.Before(end)
+	default:
+		return !t.Before(start) && t.Before(end)
+	}
+}
+
+func compareInt(n int, op string, target int) bool {
+	switch op {
+	case ">":
+		return n > target
+	case ">=":
+		return n >= target
+	case "<":
+		return n < target
+	case "<=":
+		return n <= target
+	default:
+		return n == target
+	}
+}
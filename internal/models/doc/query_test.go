@@ -0,0 +1,116 @@
+package doc
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQueryErrors(t *testing.T) {
+
+	tests := []struct {
+		raw     string
+		errText string
+	}{
+		{raw: `name:"Visual Studio`, errText: "引号未闭合"},
+		{raw: `"unterminated`, errText: "引号未闭合"},
+		{raw: `tag:`, errText: "缺少值"},
+		{raw: `-sort:name`, errText: "不能取反"},
+		{raw: `sort:unknown`},
+		{raw: `limit:-1`, errText: "非负整数"},
+		{raw: `updated:>2025-13`, errText: "日期格式错误"},
+		{raw: `weight:>high`, errText: "必须为整数"},
+		{raw: `color:red`, errText: "不支持的查询字段"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			q, err := ParseQuery(tt.raw)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) = %+v, want error", tt.raw, q)
+			}
+			if !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("error = %q, want containing %q", err, tt.errText)
+			}
+		})
+	}
+}
+
+func TestQueryApply(t *testing.T) {
+
+	date := func(s string) time.Time {
+		v, err := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	docs := []Document{
+		{Slug: "ai/a", CateSlug: "ai", Name: "Alpha", Tags: []string{"Image"}, Sort: 10, Url: "https://a.com",
+			CreateTime: date("2024-12-31 23:59"), UpdateTime: date("2025-06-01 00:00")},
+		{Slug: "ai/b", CateSlug: "ai", Name: "Visual Studio", Description: "IDE", Sort: 50, Url: "https://b.com",
+			CreateTime: date("2025-01-01 00:00"), UpdateTime: date("2025-06-30 23:59")},
+		{Slug: "dev/c", CateSlug: "dev", Name: "Gamma", Keywords: "editor", Tags: []string{"image", "free"}, Sort: 30, Url: "https://c.org",
+			CreateTime: date("2025-03-15 12:00"), UpdateTime: date("2025-07-01 00:00")},
+	}
+
+	tests := []struct {
+		raw  string
+		want []string // 按结果顺序的 slug
+	}{
+		{raw: "", want: []string{"ai/a", "ai/b", "dev/c"}},
+		{raw: "category:ai", want: []string{"ai/a", "ai/b"}},
+		{raw: "cate:dev", want: []string{"dev/c"}},
+		{raw: "tag:image", want: []string{"ai/a", "dev/c"}},
+		{raw: "-tag:image", want: []string{"ai/b"}},
+		{raw: `name:"visual studio"`, want: []string{"ai/b"}},
+		{raw: "url:.org", want: []string{"dev/c"}},
+		{raw: "editor", want: []string{"dev/c"}},
+		{raw: "ide", want: []string{"ai/b"}},
+		{raw: `"visual studio"`, want: []string{"ai/b"}},
+		{raw: `-"Visual Studio"`, want: []string{"ai/a", "dev/c"}},
+		{raw: `"studio: x"`},
+		{raw: `""`, want: []string{"ai/a", "ai/b", "dev/c"}},
+		{raw: "weight:>=30", want: []string{"ai/b", "dev/c"}},
+		{raw: "weight:30", want: []string{"dev/c"}},
+
+		// 日期按精度比较
+		{raw: "created:2025", want: []string{"ai/b", "dev/c"}},
+		{raw: "created:<2025", want: []string{"ai/a"}},
+		{raw: "created:<=2024", want: []string{"ai/a"}},
+		{raw: "created:>2024", want: []string{"ai/b", "dev/c"}},
+		{raw: "created:>=2025-03", want: []string{"dev/c"}},
+		{raw: "created:2025-01-01", want: []string{"ai/b"}},
+		{raw: "created:>2025-01-01", want: []string{"dev/c"}},
+		{raw: "created:<=2024-12-31", want: []string{"ai/a"}},
+		{raw: "updated:2025-06", want: []string{"ai/a", "ai/b"}},
+		{raw: "updated:>2025-06", want: []string{"dev/c"}},
+		{raw: "updated:<=2025-06", want: []string{"ai/a", "ai/b"}},
+		{raw: "updated:<2025-06", want: nil},
+		{raw: "-updated:2025-06", want: []string{"dev/c"}},
+		{raw: `updated:"2025-06-30 23:59:00"`, want: []string{"ai/b"}},
+
+		{raw: "sort:-sort", want: []string{"ai/b", "dev/c", "ai/a"}},
+		{raw: "tag:image sort:-sort limit:1", want: []string{"dev/c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+
+			q, err := ParseQuery(tt.raw)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.raw, err)
+			}
+
+			var got []string
+			for _, d := range q.Apply(docs) {
+				got = append(got, d.Slug)
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Apply(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package doc
 
 import (
+	"fmt"
 	"sort"
 )
 
@@ -21,6 +22,28 @@ const (
 	Descending SortOrder = "desc" // 降序
 )
 
+// ParseSortBy 解析排序字段，支持 created、updated 简写
+func ParseSortBy(value string) (SortBy, error) {
+	switch SortBy(value) {
+	case SortBySort, SortByCreateTime, SortByUpdateTime:
+		return SortBy(value), nil
+	case "created":
+		return SortByCreateTime, nil
+	case "updated":
+		return SortByUpdateTime, nil
+	}
+	return "", fmt.Errorf("不支持的排序字段 %q", value)
+}
+
+// ParseSortOrder 解析排序顺序，只支持 asc 和 desc
+func ParseSortOrder(value string) (SortOrder, error) {
+	switch SortOrder(value) {
+	case Ascending, Descending:
+		return SortOrder(value), nil
+	}
+	return "", fmt.Errorf("不支持的排序顺序 %q，可选 asc、desc", value)
+}
+
 // DocumentSorter 文档排序器
 type DocumentSorter struct {
 	documents []Document
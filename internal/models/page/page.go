@@ -0,0 +1,175 @@
+package page
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/markdown"
+)
+
+// Page 独立页面，如关于、收录说明，按 slug 对应的路径访问
+type Page struct {
+	Name        string    `json:"name"`
+	Keywords    string    `json:"keywords"`
+	Description string    `json:"description"`
+	Slug        string    `json:"slug"` // 访问路径，不含开头的 /
+	Image       string    `json:"image"`
+	Markdown    string    `json:"markdown"`
+	Sort        int       `json:"sort"` // 在菜单中的排序，越小越靠前
+	Menu        []string  `json:"menu"` // 出现在哪些菜单中
+	Layout      string    `json:"layout"`
+	Template    string    `json:"template"`
+	Published   bool      `json:"published"`
+	IsShow      bool      `json:"is_show"`
+	Custom      any       `json:"custom"`
+	CreateTime  time.Time `json:"create_time"`
+	UpdateTime  time.Time `json:"update_time"`
+	Source      string    `json:"source"` // 来源文件路径
+
+	markdown.Rendered // 加载时预先渲染的正文、目录和阅读时间
+}
+
+// IsVisible 页面是否对访客可见
+func (p Page) IsVisible() bool {
+	return p.Published && p.IsShow
+}
+
+type PagesMap struct {
+	pages map[string]Page
+	mx    sync.RWMutex
+}
+
+func New(ctx *core.Context) (*PagesMap, error) {
+
+	pages, err := getAllPages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PagesMap{pages: pages}, nil
+}
+
+// GetPagesSlice 获取按排序权重升序的页面数组
+func (p *PagesMap) GetPagesSlice() []Page {
+
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	var pages []Page
+	for _, v := range p.pages {
+		pages = append(pages, v)
+	}
+
+	sort.SliceStable(pages, func(i, j int) bool {
+		if pages[i].Sort == pages[j].Sort {
+			return pages[i].Slug < pages[j].Slug
+		}
+		return pages[i].Sort < pages[j].Sort
+	})
+
+	return pages
+}
+
+// GetPageBySlug 根据slug获取页面
+func (p *PagesMap) GetPageBySlug(slug string) *Page {
+
+	p.mx.RLock()
+	defer p.mx.RUnlock()
+
+	page, ok := p.pages[slug]
+	if ok {
+		return &page
+	}
+
+	return nil
+}
+
+func getAllPages(ctx *core.Context) (map[string]Page, error) {
+
+	pages := make(map[string]Page)
+
+	dirPath := ctx.Conf.GetString("server.pages_dir")
+	if dirPath == "" {
+		dirPath = "./pages/"
+	}
+
+	// 页面目录是可选的
+	info, err := os.Stat(dirPath)
+	if os.IsNotExist(err) {
+		return pages, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return pages, nil
+	}
+
+	err = filepath.WalkDir(dirPath, func(pathName string, d fs.DirEntry, err error) error {
+		if err != nil {
+			ctx.Log.Error(err.Error())
+			return err
+		}
+
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".md") {
+			return nil
+		}
+
+		mdCont, err := markdown.Parser(pathName)
+		if err != nil {
+			ctx.Log.Error(err.Error())
+			return nil // 继续处理其他文件
+		}
+
+		rel, err := filepath.Rel(dirPath, pathName)
+		if err != nil {
+			return nil
+		}
+
+		// front matter 中的 slug 优先，否则为相对路径
+		slug := strings.Trim(mdCont.Slug, "/")
+		if slug == "" {
+			slug = strings.TrimSuffix(filepath.ToSlash(rel), ".md")
+		}
+
+		if first, ok := pages[slug]; ok {
+			ctx.Log.Error("页面slug重复：" + slug + "，文件：" + pathName + "，已使用：" + first.Source)
+			return nil
+		}
+
+		page := Page{
+			Name:        mdCont.Name,
+			Keywords:    mdCont.Keywords,
+			Description: mdCont.Description,
+			Slug:        slug,
+			Image:       mdCont.Image,
+			Markdown:    mdCont.Markdown,
+			Sort:        mdCont.Sort,
+			Menu:        mdCont.Menu,
+			Layout:      mdCont.Layout,
+			Template:    mdCont.Template,
+			Published:   mdCont.Published,
+			IsShow:      mdCont.IsShow,
+			Custom:      mdCont.Custom,
+			CreateTime:  mdCont.CreateTime,
+			UpdateTime:  mdCont.UpdateTime,
+			Source:      pathName,
+		}
+		page.Rendered = markdown.Render(page.Markdown)
+		pages[slug] = page
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
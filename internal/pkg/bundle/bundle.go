@@ -0,0 +1,95 @@
+// Package bundle 与文档放在同一目录中的资源文件（图片、附件等），通过 /bundle/ 访问
+package bundle
+
+import (
+	"errors"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+)
+
+// Prefix 资源的访问路径前缀，后面是相对 content_dir 的路径
+const Prefix = "/bundle/"
+
+// ErrNotFound 资源不存在或不允许访问
+var ErrNotFound = errors.New("资源不存在")
+
+// IsRelative ref 是否为相对路径：没有协议和域名，不以 / 开头，也不是页内锚点
+func IsRelative(ref string) bool {
+
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "?") {
+		return false
+	}
+
+	u, err := url.Parse(ref)
+	return err == nil && u.Scheme == "" && u.Host == "" && u.Path != ""
+}
+
+// URL 把 dir（相对 content_dir 的目录）中的相对路径 ref 转换为 /bundle/ 地址，保留查询参数和锚点。
+// ref 不是相对路径或超出 content_dir 时原样返回
+func URL(dir, ref string) string {
+
+	if !IsRelative(ref) {
+		return ref
+	}
+
+	u, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return ref
+	}
+
+	name := path.Join(dir, u.Path)
+	if !Valid(name) {
+		return ref
+	}
+
+	u.Path = Prefix + name
+	return u.String()
+}
+
+// Valid name 是否为可以访问的资源路径：相对路径、不含 ..，且任何一级都不是隐藏文件
+func Valid(name string) bool {
+
+	if !fs.ValidPath(name) || name == "." {
+		return false
+	}
+
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Open 打开 root 目录中的资源文件。通过 os.Root 打开，符号链接也不能指向 root 之外，目录不作为资源
+func Open(root, name string) (*os.File, fs.FileInfo, error) {
+
+	name = strings.TrimPrefix(name, "/")
+	if !Valid(name) {
+		return nil, nil, ErrNotFound
+	}
+
+	r, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Close()
+
+	file, err := r.Open(name)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	return file, info, nil
+}
@@ -0,0 +1,123 @@
+package bundle
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestURL(t *testing.T) {
+
+	tests := []struct {
+		dir  string
+		ref  string
+		want string
+	}{
+		{dir: "dev", ref: "github.png", want: "/bundle/dev/github.png"},
+		{dir: "dev", ref: "./img/a b.png?v=2#x", want: "/bundle/dev/img/a%20b.png?v=2#x"},
+		{dir: "dev/tools", ref: "../logo.png", want: "/bundle/dev/logo.png"},
+		{dir: "", ref: "logo.png", want: "/bundle/logo.png"},
+
+		// 超出 content_dir 或隐藏文件时原样返回
+		{dir: "dev", ref: "../../etc/passwd", want: "../../etc/passwd"},
+		{dir: "", ref: "../x.png", want: "../x.png"},
+		{dir: "dev", ref: "./../../x.png", want: "./../../x.png"},
+		{dir: "dev", ref: ".git/config", want: ".git/config"},
+		{dir: "dev", ref: "img/.secret.png", want: "img/.secret.png"},
+		{dir: "dev", ref: "..", want: ".."},
+		{dir: "dev", ref: "%2e%2e/%2e%2e/x.png", want: "%2e%2e/%2e%2e/x.png"},
+
+		// 不是相对路径
+		{dir: "dev", ref: "/static/a.png", want: "/static/a.png"},
+		{dir: "dev", ref: "https://example.com/a.png", want: "https://example.com/a.png"},
+		{dir: "dev", ref: "//example.com/a.png", want: "//example.com/a.png"},
+		{dir: "dev", ref: "data:image/png;base64,xx", want: "data:image/png;base64,xx"},
+		{dir: "dev", ref: "#top", want: "#top"},
+		{dir: "dev", ref: "?page=2", want: "?page=2"},
+		{dir: "dev", ref: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dir+"|"+tt.ref, func(t *testing.T) {
+			if got := URL(tt.dir, tt.ref); got != tt.want {
+				t.Errorf("URL(%q, %q) = %q, want %q", tt.dir, tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	files := map[string]string{
+		"dev/github.png":  "png",
+		"dev/.hidden.png": "hidden",
+		"dev/img/a b.png": "space",
+	}
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "dev", "link.txt")); err != nil {
+		t.Skip("不支持符号链接：", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "dev", "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("github.png", filepath.Join(root, "dev", "alias.png")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		want string // 为空时期望 ErrNotFound
+	}{
+		{name: "dev/github.png", want: "png"},
+		{name: "/dev/github.png", want: "png"},
+		{name: "dev/img/a b.png", want: "space"},
+		{name: "dev/alias.png", want: "png"},
+		{name: "dev/../dev/github.png"},
+		{name: "../" + filepath.Base(outside) + "/secret.txt"},
+		{name: "dev/../../" + filepath.Base(outside) + "/secret.txt"},
+		{name: "dev/link.txt"},
+		{name: "dev/linkdir/secret.txt"},
+		{name: "dev/.hidden.png"},
+		{name: "dev"},
+		{name: "dev/missing.png"},
+		{name: ""},
+		{name: "dev\\..\\..\\secret.txt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			file, info, err := Open(root, tt.name)
+			if tt.want == "" {
+				if !errors.Is(err, ErrNotFound) {
+					t.Fatalf("Open(%q) error = %v, want ErrNotFound", tt.name, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Open(%q): %v", tt.name, err)
+			}
+			defer file.Close()
+
+			b, _ := io.ReadAll(file)
+			if string(b) != tt.want || info.Size() != int64(len(tt.want)) {
+				t.Errorf("Open(%q) = %q (%d bytes), want %q", tt.name, b, info.Size(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package exchange
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+)
+
+// 这些标题下的内容不是链接列表，导入时跳过
+var awesomeSkipSections = map[string]bool{
+	"contents":          true,
+	"table of contents": true,
+	"contributing":      true,
+	"contribute":        true,
+	"license":           true,
+	"目录":                true,
+	"贡献":                true,
+	"许可证":               true,
+}
+
+// ParseAwesome 解析 awesome-* 风格的 README：一级标题为列表名称，二级及以下标题为多级分类，
+// 列表项 [name](url) - description 为链接。目录、贡献、许可证等章节以及非 http 链接会被跳过，
+// 出现在任何分类标题之前的链接归入 rootCategory
+func ParseAwesome(r io.Reader, rootCategory string) (*Directory, error) {
+
+	source, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	md := goldmark.New(goldmark.WithExtensions(extension.GFM))
+	doc := md.Parser().Parse(text.NewReader(source))
+
+	dir := &Directory{Fields: fieldSet(FieldName, FieldUrl, FieldDescription)}
+
+	var (
+		headings  []string // 当前标题路径，下标为标题级别-2
+		skipLevel int      // 被跳过章节的标题级别，0 表示不跳过
+	)
+
+	// category 当前标题路径对应的分类，第一次有链接时才创建
+	category := func() string {
+		slug := ""
+		for _, name := range headings {
+			if name == "" {
+				continue
+			}
+			slug = uniqueChildSlug(dir, slug, name)
+			dir.AddCategory(Category{Slug: slug, Name: name})
+		}
+		return slug
+	}
+
+	err = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch node := n.(type) {
+		case *ast.Heading:
+			name := strings.TrimSpace(nodeText(node, source))
+			if node.Level == 1 {
+				headings, skipLevel = nil, 0
+				return ast.WalkSkipChildren, nil
+			}
+			if skipLevel > 0 && node.Level > skipLevel {
+				return ast.WalkSkipChildren, nil
+			}
+			skipLevel = 0
+			if awesomeSkipSections[strings.ToLower(name)] {
+				skipLevel = node.Level
+				return ast.WalkSkipChildren, nil
+			}
+			depth := node.Level - 2
+			for len(headings) < depth {
+				headings = append(headings, "")
+			}
+			headings = append(headings[:depth], name)
+			return ast.WalkSkipChildren, nil
+
+		case *ast.ListItem:
+			if skipLevel > 0 {
+				return ast.WalkSkipChildren, nil
+			}
+			if entry, ok := awesomeEntry(node, source); ok {
+				entry.Category = category()
+				dir.Entries = append(dir.Entries, entry)
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fillRootCategory(dir, rootCategory)
+
+	return dir, nil
+}
+
+// awesomeEntry 从列表项的第一段中取出链接及其后的描述
+func awesomeEntry(item *ast.ListItem, source []byte) (Entry, bool) {
+
+	block := item.FirstChild()
+	if block == nil {
+		return Entry{}, false
+	}
+
+	var entry Entry
+	var desc strings.Builder
+	found := false
+
+	for c := block.FirstChild(); c != nil; c = c.NextSibling() {
+		if found {
+			desc.WriteString(nodeText(c, source))
+			continue
+		}
+		switch link := c.(type) {
+		case *ast.Link:
+			entry.Url = string(link.Destination)
+			entry.Name = strings.TrimSpace(nodeText(link, source))
+			found = true
+		case *ast.AutoLink:
+			entry.Url = string(link.URL(source))
+			entry.Name = entry.Url
+			found = true
+		}
+	}
+
+	if !found || !(strings.HasPrefix(entry.Url, "http://") || strings.HasPrefix(entry.Url, "https://")) {
+		return Entry{}, false
+	}
+	if entry.Name == "" {
+		entry.Name = entry.Url
+	}
+
+	entry.Description = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(desc.String()), "-–—:："))
+
+	return entry, true
+}
+
+// nodeText 节点内的纯文本，图片（徽章）忽略
+func nodeText(n ast.Node, source []byte) string {
+
+	var b strings.Builder
+
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node := c.(type) {
+		case *ast.Image:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			b.Write(node.Segment.Value(source))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return b.String()
+}
+
+// WriteAwesome 生成 awesome-* 风格的 README，分类为标题并附带目录
+func WriteAwesome(w io.Writer, dir *Directory, title, description string) error {
+
+	bw := bufio.NewWriter(w)
+	children := categoryTree(dir)
+	anchors := make(map[string]int)
+
+	fmt.Fprintf(bw, "# %s\n\n", title)
+	if description != "" {
+		fmt.Fprintf(bw, "> %s\n\n", oneLine(description))
+	}
+
+	fmt.Fprint(bw, "## Contents\n\n")
+	anchors["contents"]++
+	writeAwesomeToc(bw, dir, children, "", 0, anchors)
+	fmt.Fprintln(bw)
+
+	writeAwesomeSection(bw, dir, children, "", 2)
+
+	return bw.Flush()
+}
+
+func writeAwesomeToc(w *bufio.Writer, dir *Directory, children map[string][]string, slug string, depth int, anchors map[string]int) {
+	for _, child := range children[slug] {
+		name := dir.CategoryName(child)
+		fmt.Fprintf(w, "%s- [%s](#%s)\n", strings.Repeat("  ", depth), escapeLinkText(name), headingAnchor(name, anchors))
+		writeAwesomeToc(w, dir, children, child, depth+1, anchors)
+	}
+}
+
+func writeAwesomeSection(w *bufio.Writer, dir *Directory, children map[string][]string, slug string, level int) {
+
+	if slug != "" {
+		fmt.Fprintf(w, "%s %s\n\n", strings.Repeat("#", min(level, 6)), oneLine(dir.CategoryName(slug)))
+		level++
+
+		for _, c := range dir.Categories {
+			if c.Slug == slug && c.Description != "" {
+				fmt.Fprintf(w, "%s\n\n", oneLine(c.Description))
+			}
+		}
+
+		entries := dir.CategoryEntries(slug)
+		for _, e := range entries {
+			fmt.Fprintf(w, "- [%s](%s)", escapeLinkText(e.Name), strings.ReplaceAll(e.Url, ")", "%29"))
+			if e.Description != "" {
+				fmt.Fprintf(w, " - %s", oneLine(e.Description))
+			}
+			fmt.Fprintln(w)
+		}
+		if len(entries) > 0 {
+			fmt.Fprintln(w)
+		}
+	}
+
+	for _, child := range children[slug] {
+		writeAwesomeSection(w, dir, children, child, level)
+	}
+}
+
+// headingAnchor 按 GitHub 的规则生成标题锚点，重复的标题追加序号
+func headingAnchor(name string, anchors map[string]int) string {
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+
+	anchor := b.String()
+	n := anchors[anchor]
+	anchors[anchor]++
+	if n > 0 {
+		anchor = fmt.Sprintf("%s-%d", anchor, n)
+	}
+
+	return anchor
+}
+
+func escapeLinkText(s string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(oneLine(s))
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
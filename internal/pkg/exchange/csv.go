@@ -0,0 +1,199 @@
+package exchange
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"mdnav/internal/pkg/markdown"
+)
+
+// CSV 中分类相关的列
+const (
+	FieldCategory     = "category"      // 分类路径，每一级可以是 slug 或名称
+	FieldCategoryName = "category_name" // 分类名称，导出时写入以便原样导回
+)
+
+// CsvFields CSV 支持的字段，顺序即导出列顺序
+var CsvFields = []string{FieldCategory, FieldCategoryName, FieldName, FieldUrl, FieldDescription, FieldTags, FieldIcon, FieldSort, FieldCreateTime}
+
+// CsvMapping 字段 -> 表头名称，未配置的字段以字段名作为表头
+type CsvMapping map[string]string
+
+// ParseCsvMapping 解析 name=标题,url=链接 形式的列映射
+func ParseCsvMapping(s string) (CsvMapping, error) {
+
+	mapping := make(CsvMapping)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("列映射 %q 格式错误，应为 字段=表头", pair)
+		}
+		mapping[strings.TrimSpace(field)] = strings.TrimSpace(column)
+	}
+
+	return mapping, mapping.validate()
+}
+
+func (m CsvMapping) validate() error {
+	for field := range m {
+		if !fieldSet(CsvFields...)[field] {
+			return fmt.Errorf("未知字段 %q，可用字段：%s", field, strings.Join(CsvFields, ", "))
+		}
+	}
+	return nil
+}
+
+// Column 字段对应的表头
+func (m CsvMapping) Column(field string) string {
+	if v := m[field]; v != "" {
+		return v
+	}
+	return field
+}
+
+// ParseCsv 解析 CSV，表头按列映射匹配（不区分大小写），必须包含 url 列，
+// 没有分类列或分类为空的行归入 rootCategory
+func ParseCsv(r io.Reader, mapping CsvMapping, rootCategory string) (*Directory, error) {
+
+	if err := mapping.validate(); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return &Directory{Fields: fieldSet()}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int) // 字段 -> 列序号
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		for _, field := range CsvFields {
+			if strings.EqualFold(name, mapping.Column(field)) {
+				columns[field] = i
+			}
+		}
+	}
+
+	if _, ok := columns[FieldUrl]; !ok {
+		return nil, fmt.Errorf("CSV 表头缺少 %s 列", mapping.Column(FieldUrl))
+	}
+
+	dir := &Directory{Fields: fieldSet()}
+	for field := range columns {
+		dir.Fields[field] = true
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		get := func(field string) string {
+			if i, ok := columns[field]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		entry := Entry{
+			Name:        get(FieldName),
+			Url:         get(FieldUrl),
+			Description: get(FieldDescription),
+			Tags:        markdown.SplitTags(get(FieldTags)),
+			Icon:        get(FieldIcon),
+		}
+		if entry.Url == "" {
+			continue
+		}
+		if entry.Name == "" {
+			entry.Name = entry.Url
+		}
+
+		if v := get(FieldSort); v != "" {
+			if entry.Sort, err = strconv.Atoi(v); err != nil {
+				return nil, fmt.Errorf("第 %d 行：sort 必须为整数: %q", line, v)
+			}
+		}
+
+		if v := get(FieldCreateTime); v != "" {
+			if entry.CreateTime, err = markdown.ParseTime(v); err != nil {
+				return nil, fmt.Errorf("第 %d 行：%w", line, err)
+			}
+		}
+
+		entry.Category = dir.AddCategoryPath(get(FieldCategory))
+		if name := get(FieldCategoryName); name != "" && entry.Category != "" {
+			dir.setCategoryName(entry.Category, name)
+		}
+
+		dir.Entries = append(dir.Entries, entry)
+	}
+
+	fillRootCategory(dir, rootCategory)
+
+	return dir, nil
+}
+
+// WriteCsv 导出为 CSV，可以按相同的列映射原样导回
+func WriteCsv(w io.Writer, dir *Directory, mapping CsvMapping) error {
+
+	if err := mapping.validate(); err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+
+	header := make([]string, len(CsvFields))
+	for i, field := range CsvFields {
+		header[i] = mapping.Column(field)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, c := range dir.SortedCategories() {
+		for _, e := range dir.CategoryEntries(c.Slug) {
+			sort, createTime := "", ""
+			if e.Sort != 0 {
+				sort = strconv.Itoa(e.Sort)
+			}
+			if !e.CreateTime.IsZero() {
+				createTime = e.CreateTime.Format(time.RFC3339)
+			}
+			row := []string{c.Slug, c.Name, e.Name, e.Url, e.Description, strings.Join(e.Tags, ";"), e.Icon, sort, createTime}
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (d *Directory) setCategoryName(slug, name string) {
+	for i := range d.Categories {
+		if d.Categories[i].Slug == slug {
+			d.Categories[i].Name = name
+		}
+	}
+}
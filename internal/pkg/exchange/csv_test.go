@@ -0,0 +1,185 @@
+package exchange
+
+import (
+	"bytes"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseCsv(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		mapping string
+		input   string
+		want    []string
+		fields  []string // 导入数据包含的链接字段
+		err     string   // 期望的错误信息片段
+	}{
+		{
+			name: "all columns",
+			input: "category,category_name,name,url,description,tags,icon,sort,create_time\n" +
+				"dev/tools,开发工具,GitHub,https://github.com,\"Code, \"\"git\"\"\",code;git,/i.png,3,2024-05-01T08:30:00Z\n" +
+				"dev,,,https://go.dev,,,,,\n",
+			want: []string{
+				"dev=dev",
+				"dev/tools=开发工具",
+				`dev/tools|GitHub|https://github.com|Code, "git"|code,git|/i.png|1714552200`,
+				"dev|https://go.dev|https://go.dev||||",
+			},
+			fields: []string{"category", "category_name", "create_time", "description", "icon", "name", "sort", "tags", "url"},
+		},
+		{
+			name:    "mapped headers with bom",
+			mapping: "name=标题,url=链接,category=分组",
+			input:   "\ufeff 标题 ,链接,分组,备注\nGo,https://go.dev,Dev Tools/语言,ignored\n",
+			want: []string{
+				"dev-tools=Dev Tools",
+				"dev-tools/folder-9f6fee1a=语言",
+				"dev-tools/folder-9f6fee1a|Go|https://go.dev||||",
+			},
+			fields: []string{"category", "name", "url"},
+		},
+		{
+			name:  "header case and short rows",
+			input: "URL,Name\nhttps://a.com\nhttps://b.com,B,extra\n,skipped\n",
+			want: []string{
+				"imported=imported",
+				"imported|https://a.com|https://a.com||||",
+				"imported|B|https://b.com||||",
+			},
+			fields: []string{"name", "url"},
+		},
+		{
+			name:  "traversal in category",
+			input: "url,category\nhttps://x.com,../../etc\nhttps://y.com,/abs/./x\n",
+			want: []string{
+				"folder-5ec1f7e7=..",
+				"folder-5ec1f7e7/folder-5ec1f7e7=..",
+				"folder-5ec1f7e7/folder-5ec1f7e7/etc=etc",
+				"abs=abs",
+				"abs/folder-cdb4ee2a=.",
+				"abs/folder-cdb4ee2a/x=x",
+				"folder-5ec1f7e7/folder-5ec1f7e7/etc|https://x.com|https://x.com||||",
+				"abs/folder-cdb4ee2a/x|https://y.com|https://y.com||||",
+			},
+			fields: []string{"category", "url"},
+		},
+		{
+			name:   "empty file",
+			input:  "",
+			fields: []string{},
+		},
+		{
+			name:   "header only",
+			input:  "url\n",
+			fields: []string{"url"},
+		},
+		{name: "missing url column", input: "name,link\nGo,https://go.dev\n", err: "CSV 表头缺少 url 列"},
+		{name: "missing mapped url column", mapping: "url=链接", input: "url\nhttps://go.dev\n", err: "CSV 表头缺少 链接 列"},
+		{name: "bad sort", input: "url,sort\nhttps://a.com,1\nhttps://b.com,x\n", err: "第 3 行：sort 必须为整数"},
+		{name: "bad create_time", input: "url,create_time\nhttps://a.com,yesterday\n", err: "第 2 行："},
+		{name: "bad quote", input: "url,name\nhttps://a.com,\"unterminated\n", err: "extraneous or missing \" in quoted-field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			mapping, err := ParseCsvMapping(tt.mapping)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			dir, err := ParseCsv(strings.NewReader(tt.input), mapping, "imported")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := summary(dir), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+
+			fields := []string{}
+			for field := range dir.Fields {
+				fields = append(fields, field)
+			}
+			sort.Strings(fields)
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %q, want %q", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestParseCsvMapping(t *testing.T) {
+
+	tests := []struct {
+		raw  string
+		want CsvMapping
+		err  string
+	}{
+		{raw: "", want: CsvMapping{}},
+		{raw: " name = 标题 , url=链接,", want: CsvMapping{"name": "标题", "url": "链接"}},
+		{raw: "name", err: "格式错误"},
+		{raw: "title=标题", err: "未知字段"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := ParseCsvMapping(tt.raw)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseCsvMapping(%q) error = %v, want containing %q", tt.raw, err, tt.err)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCsvMapping(%q) = %v, %v, want %v", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCsvRoundTrip(t *testing.T) {
+
+	dir := &Directory{
+		Categories: []Category{
+			{Slug: "dev", Name: "Dev, \"Ops\""},
+			{Slug: "dev/docs", Name: "文档"},
+		},
+		Entries: []Entry{
+			{Category: "dev", Name: "A", Url: "https://a.com/?x=1,2", Description: "line1\nline2", Tags: []string{"x", "y"},
+				Icon: "https://a.com/i.png", Sort: -2, CreateTime: time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)},
+			{Category: "dev/docs", Name: "B", Url: "https://b.com"},
+		},
+	}
+
+	mapping := CsvMapping{"name": "标题", "url": "链接"}
+
+	var buf bytes.Buffer
+	if err := WriteCsv(&buf, dir, mapping); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+
+	parsed, err := ParseCsv(strings.NewReader(exported), mapping, "imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := summary(parsed), summary(dir); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s\nexported:\n%s", got, want, exported)
+	}
+	if parsed.Entries[0].Sort != -2 {
+		t.Errorf("sort = %d, want -2", parsed.Entries[0].Sort)
+	}
+}
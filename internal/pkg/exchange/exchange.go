@@ -0,0 +1,193 @@
+package exchange
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"mdnav/internal/utils"
+)
+
+// Category 导入导出时的分类，Slug 为相对内容目录的路径，多级用 / 分隔
+type Category struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Sort        int    `json:"sort"`
+}
+
+// Entry 导入导出时的单个链接
+type Entry struct {
+	Category    string    `json:"category"` // 所属分类的 Slug
+	Name        string    `json:"name"`
+	Url         string    `json:"url"`
+	Description string    `json:"description"`
+	Tags        []string  `json:"tags"`
+	Icon        string    `json:"icon"`
+	Sort        int       `json:"sort"`
+	CreateTime  time.Time `json:"create_time"`
+}
+
+// 链接字段名，用于 Directory.Fields 及 CSV 列映射
+const (
+	FieldName        = "name"
+	FieldUrl         = "url"
+	FieldDescription = "description"
+	FieldTags        = "tags"
+	FieldIcon        = "icon"
+	FieldSort        = "sort"
+	FieldCreateTime  = "create_time"
+)
+
+// Directory 导入导出的完整目录
+type Directory struct {
+	Categories []Category      `json:"categories"`
+	Entries    []Entry         `json:"entries"`
+	Fields     map[string]bool `json:"-"` // 导入数据实际包含的链接字段，更新已有文档时只覆盖这些字段
+}
+
+// AddCategory 添加分类，已存在时忽略
+func (d *Directory) AddCategory(c Category) {
+	for _, v := range d.Categories {
+		if v.Slug == c.Slug {
+			return
+		}
+	}
+	d.Categories = append(d.Categories, c)
+}
+
+// CategoryEntries 获取分类下的链接，保持原顺序
+func (d *Directory) CategoryEntries(slug string) []Entry {
+	var entries []Entry
+	for _, e := range d.Entries {
+		if e.Category == slug {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// SortedCategories 按 Slug 排序的分类，父分类排在子分类之前
+func (d *Directory) SortedCategories() []Category {
+	cates := append([]Category(nil), d.Categories...)
+	sort.SliceStable(cates, func(i, j int) bool {
+		if cates[i].Sort != cates[j].Sort && parentSlug(cates[i].Slug) == parentSlug(cates[j].Slug) {
+			return cates[i].Sort < cates[j].Sort
+		}
+		return cates[i].Slug < cates[j].Slug
+	})
+	return cates
+}
+
+// CategoryName 获取分类名称，未登记时使用 Slug 最后一段
+func (d *Directory) CategoryName(slug string) string {
+	for _, v := range d.Categories {
+		if v.Slug == slug && v.Name != "" {
+			return v.Name
+		}
+	}
+	return slug[strings.LastIndex(slug, "/")+1:]
+}
+
+// AddCategoryPath 按 / 分隔的分类路径添加分类，每一级可以是 slug 或名称，返回分类 slug
+func (d *Directory) AddCategoryPath(value string) string {
+
+	slug := ""
+	for _, part := range strings.Split(value, "/") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if utils.Slugify(part) == part {
+			slug = path.Join(slug, part)
+		} else {
+			slug = uniqueChildSlug(d, slug, part)
+		}
+		d.AddCategory(Category{Slug: slug, Name: part})
+	}
+
+	return slug
+}
+
+func fieldSet(fields ...string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range fields {
+		set[v] = true
+	}
+	return set
+}
+
+func parentSlug(slug string) string {
+	if i := strings.LastIndex(slug, "/"); i >= 0 {
+		return slug[:i]
+	}
+	return ""
+}
+
+// 支持的导入导出格式
+const (
+	FormatBookmarks = "bookmarks" // 浏览器书签 HTML
+	FormatCsv       = "csv"
+	FormatOpml      = "opml"
+	FormatAwesome   = "awesome" // awesome-* 风格的 README
+)
+
+// Formats 全部格式
+var Formats = []string{FormatBookmarks, FormatCsv, FormatOpml, FormatAwesome}
+
+// Options 导入导出选项
+type Options struct {
+	RootCategory string     // 导入时未分组链接所属的分类
+	CsvMapping   CsvMapping // CSV 列映射
+	Title        string     // 导出文件标题
+	Description  string     // 导出文件的简介，用于 README
+}
+
+// Parse 按格式解析导入数据
+func Parse(format string, r io.Reader, opts Options) (*Directory, error) {
+	switch format {
+	case FormatBookmarks:
+		return ParseNetscape(r, opts.RootCategory)
+	case FormatCsv:
+		return ParseCsv(r, opts.CsvMapping, opts.RootCategory)
+	case FormatOpml:
+		return ParseOpml(r, opts.RootCategory)
+	case FormatAwesome:
+		return ParseAwesome(r, opts.RootCategory)
+	}
+	return nil, unknownFormat(format)
+}
+
+// Write 按格式导出
+func Write(format string, w io.Writer, dir *Directory, opts Options) error {
+	switch format {
+	case FormatBookmarks:
+		return WriteNetscape(w, dir, opts.Title)
+	case FormatCsv:
+		return WriteCsv(w, dir, opts.CsvMapping)
+	case FormatOpml:
+		return WriteOpml(w, dir, opts.Title)
+	case FormatAwesome:
+		return WriteAwesome(w, dir, opts.Title, opts.Description)
+	}
+	return unknownFormat(format)
+}
+
+// FileName 导出下载时的文件名及 Content-Type
+func FileName(format string) (name, contentType string) {
+	switch format {
+	case FormatCsv:
+		return "links.csv", "text/csv; charset=utf-8"
+	case FormatOpml:
+		return "links.opml", "text/x-opml; charset=utf-8"
+	case FormatAwesome:
+		return "README.md", "text/markdown; charset=utf-8"
+	}
+	return "bookmarks.html", "text/html; charset=utf-8"
+}
+
+func unknownFormat(format string) error {
+	return fmt.Errorf("不支持的格式 %q，可用格式：%s", format, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,296 @@
+package exchange
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mdnav/internal/utils"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// ParseNetscape 解析浏览器导出的 Netscape 书签 HTML。
+// 文件夹转换为分类（多级文件夹对应多级目录），不在任何文件夹中的书签归入 rootCategory，
+// 书签栏文件夹（PERSONAL_TOOLBAR_FOLDER）本身不生成分类
+func ParseNetscape(r io.Reader, rootCategory string) (*Directory, error) {
+
+	dir := &Directory{Fields: fieldSet(FieldName, FieldUrl, FieldDescription, FieldTags, FieldIcon, FieldCreateTime)}
+	z := xhtml.NewTokenizer(r)
+
+	var (
+		stack     []string  // 当前所在分类 slug 栈
+		pending   *Category // 最近一个 H3，等待后面的 DL 展开
+		text      strings.Builder
+		attrs     map[string]string
+		inH3      bool
+		inA       bool
+		inDD      bool
+		lastEntry = -1
+	)
+
+	current := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1]
+	}
+
+	finishDD := func() {
+		if inDD && lastEntry >= 0 {
+			dir.Entries[lastEntry].Description = strings.TrimSpace(text.String())
+		}
+		inDD = false
+	}
+
+	for {
+		tt := z.Next()
+		switch tt {
+		case xhtml.ErrorToken:
+			if z.Err() == io.EOF {
+				fillRootCategory(dir, rootCategory)
+				return dir, nil
+			}
+			return nil, z.Err()
+
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				finishDD()
+				inH3 = true
+				attrs = tokenAttrs(z)
+				text.Reset()
+			case "a":
+				finishDD()
+				inA = true
+				attrs = tokenAttrs(z)
+				text.Reset()
+			case "dd":
+				inDD = true
+				text.Reset()
+			case "dt":
+				finishDD()
+			case "dl":
+				finishDD()
+				if pending != nil {
+					stack = append(stack, pending.Slug)
+					pending = nil
+				} else {
+					stack = append(stack, current())
+				}
+			}
+
+		case xhtml.EndTagToken:
+			name, _ := z.TagName()
+			switch string(name) {
+			case "h3":
+				inH3 = false
+				folder := strings.TrimSpace(text.String())
+				if attrs["personal_toolbar_folder"] == "true" {
+					pending = &Category{Slug: current()}
+					continue
+				}
+				c := Category{
+					Slug: uniqueChildSlug(dir, current(), folder),
+					Name: folder,
+				}
+				dir.AddCategory(c)
+				pending = &c
+			case "a":
+				inA = false
+				href := strings.TrimSpace(attrs["href"])
+				if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+					continue // 跳过 javascript:、place: 等非网页书签
+				}
+				entry := Entry{
+					Category: current(),
+					Name:     strings.TrimSpace(text.String()),
+					Url:      href,
+					Tags:     splitList(attrs["tags"]),
+				}
+				if entry.Name == "" {
+					entry.Name = href
+				}
+				if sec, err := strconv.ParseInt(attrs["add_date"], 10, 64); err == nil && sec > 0 {
+					entry.CreateTime = time.Unix(sec, 0)
+				}
+				if icon := attrs["icon_uri"]; strings.HasPrefix(icon, "http") {
+					entry.Icon = icon
+				}
+				dir.Entries = append(dir.Entries, entry)
+				lastEntry = len(dir.Entries) - 1
+			case "dl":
+				finishDD()
+				if len(stack) > 0 {
+					stack = stack[:len(stack)-1]
+				}
+			}
+
+		case xhtml.TextToken:
+			if inH3 || inA || inDD {
+				text.Write(z.Text())
+			}
+		}
+	}
+}
+
+// WriteNetscape 生成 Netscape 书签 HTML，多级分类生成嵌套文件夹
+func WriteNetscape(w io.Writer, dir *Directory, title string) error {
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "<!DOCTYPE NETSCAPE-Bookmark-file-1>")
+	fmt.Fprintln(bw, "<!-- This is an automatically generated file. -->")
+	fmt.Fprintln(bw, `<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">`)
+	fmt.Fprintf(bw, "<TITLE>%s</TITLE>\n", html.EscapeString(title))
+	fmt.Fprintf(bw, "<H1>%s</H1>\n", html.EscapeString(title))
+	fmt.Fprintln(bw, "<DL><p>")
+
+	children := categoryTree(dir)
+	writeNetscapeFolder(bw, dir, children, "", 1)
+
+	fmt.Fprintln(bw, "</DL><p>")
+
+	return bw.Flush()
+}
+
+func writeNetscapeFolder(w *bufio.Writer, dir *Directory, children map[string][]string, slug string, depth int) {
+
+	indent := strings.Repeat("    ", depth)
+
+	for _, e := range dir.CategoryEntries(slug) {
+		fmt.Fprintf(w, `%s<DT><A HREF="%s"`, indent, html.EscapeString(e.Url))
+		if !e.CreateTime.IsZero() {
+			fmt.Fprintf(w, ` ADD_DATE="%d"`, e.CreateTime.Unix())
+		}
+		if len(e.Tags) > 0 {
+			fmt.Fprintf(w, ` TAGS="%s"`, html.EscapeString(strings.Join(e.Tags, ",")))
+		}
+		if e.Icon != "" {
+			fmt.Fprintf(w, ` ICON_URI="%s"`, html.EscapeString(e.Icon))
+		}
+		fmt.Fprintf(w, ">%s</A>\n", html.EscapeString(e.Name))
+		if e.Description != "" {
+			fmt.Fprintf(w, "%s<DD>%s\n", indent, html.EscapeString(e.Description))
+		}
+	}
+
+	for _, child := range children[slug] {
+		fmt.Fprintf(w, "%s<DT><H3>%s</H3>\n", indent, html.EscapeString(dir.CategoryName(child)))
+		fmt.Fprintf(w, "%s<DL><p>\n", indent)
+		writeNetscapeFolder(w, dir, children, child, depth+1)
+		fmt.Fprintf(w, "%s</DL><p>\n", indent)
+	}
+}
+
+// categoryTree 父分类 slug -> 子分类 slug，缺失的中间层级自动补齐
+func categoryTree(dir *Directory) map[string][]string {
+
+	seen := make(map[string]bool)
+	children := make(map[string][]string)
+
+	var add func(slug string)
+	add = func(slug string) {
+		if slug == "" || seen[slug] {
+			return
+		}
+		seen[slug] = true
+		parent := parentSlug(slug)
+		add(parent)
+		children[parent] = append(children[parent], slug)
+	}
+
+	for _, c := range dir.SortedCategories() {
+		add(c.Slug)
+	}
+	for _, e := range dir.Entries {
+		add(e.Category)
+	}
+
+	for k := range children {
+		sort.SliceStable(children[k], func(i, j int) bool {
+			return categorySort(dir, children[k][i]) < categorySort(dir, children[k][j])
+		})
+	}
+
+	return children
+}
+
+func categorySort(dir *Directory, slug string) int {
+	for _, c := range dir.Categories {
+		if c.Slug == slug {
+			return c.Sort
+		}
+	}
+	return 0
+}
+
+// uniqueChildSlug 生成子分类 slug，同级已有同名分类时复用，不同名但 slug 相同时追加序号
+func uniqueChildSlug(dir *Directory, parent, name string) string {
+
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "folder-" + utils.GenerateShortCode(name)
+	}
+
+	slug := path.Join(parent, base)
+	for i := 2; ; i++ {
+		existing := ""
+		for _, c := range dir.Categories {
+			if c.Slug == slug {
+				existing = c.Name
+				break
+			}
+		}
+		if existing == "" || existing == name {
+			return slug
+		}
+		slug = path.Join(parent, base+"-"+strconv.Itoa(i))
+	}
+}
+
+// fillRootCategory 不在文件夹中的书签归入根分类
+func fillRootCategory(dir *Directory, rootCategory string) {
+
+	used := false
+	for i := range dir.Entries {
+		if dir.Entries[i].Category == "" {
+			dir.Entries[i].Category = rootCategory
+			used = true
+		}
+	}
+
+	if used {
+		dir.AddCategory(Category{Slug: rootCategory, Name: rootCategory})
+	}
+}
+
+func tokenAttrs(z *xhtml.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+	for {
+		key, val, more := z.TagAttr()
+		if len(key) > 0 {
+			attrs[strings.ToLower(string(key))] = string(val)
+		}
+		if !more {
+			return attrs
+		}
+	}
+}
+
+func splitList(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
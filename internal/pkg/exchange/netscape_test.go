@@ -0,0 +1,165 @@
+package exchange
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// summary 把解析结果整理为便于比较的文本：分类为 slug=名称，链接为 分类|名称|地址|描述|标签|图标|创建时间
+func summary(dir *Directory) string {
+
+	var lines []string
+	for _, c := range dir.Categories {
+		lines = append(lines, c.Slug+"="+c.Name)
+	}
+	for _, e := range dir.Entries {
+		created := ""
+		if !e.CreateTime.IsZero() {
+			created = fmt.Sprint(e.CreateTime.Unix())
+		}
+		lines = append(lines, strings.Join([]string{e.Category, e.Name, e.Url, e.Description, strings.Join(e.Tags, ","), e.Icon, created}, "|"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func TestParseNetscape(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name: "browser export",
+			input: `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1700000000" PERSONAL_TOOLBAR_FOLDER="true">Bookmarks bar</H3>
+    <DL><p>
+        <DT><A HREF="https://github.com/" ADD_DATE="1700000001" ICON_URI="https://github.com/favicon.ico" TAGS="code, git">GitHub</A>
+        <DD>Code &amp; collaboration
+        <DT><H3>Dev Tools</H3>
+        <DL><p>
+            <DT><A HREF="https://go.dev/" ICON="data:image/png;base64,xx" ICON_URI="data:image/png;base64,xx"> Go </A>
+            <DT><A HREF="javascript:alert(1)">bookmarklet</A>
+            <DT><A HREF="place:sort=8">recent</A>
+        </DL><p>
+    </DL><p>
+    <DT><A HREF="http://example.com/a?b=1&amp;c=2"></A>
+</DL><p>`,
+			want: []string{
+				"dev-tools=Dev Tools",
+				"imported=imported",
+				"imported|GitHub|https://github.com/|Code & collaboration|code,git|https://github.com/favicon.ico|1700000001",
+				"dev-tools|Go|https://go.dev/||||",
+				"imported|http://example.com/a?b=1&c=2|http://example.com/a?b=1&c=2||||",
+			},
+		},
+		{
+			name: "nested and duplicate folders",
+			input: `<DL>
+<DT><H3>Dev</H3><DL>
+  <DT><H3>Docs</H3><DL><DT><A HREF="https://a.com">A</A></DL>
+</DL>
+<DT><H3>Dev</H3><DL><DT><A HREF="https://b.com">B</A></DL>
+<DT><H3>dev!</H3><DL><DT><A HREF="https://c.com">C</A></DL>
+<DT><H3>Ops</H3><DL>
+  <DT><H3>Docs</H3><DL><DT><A HREF="https://d.com">D</A></DL>
+</DL>
+</DL>`,
+			want: []string{
+				"dev=Dev",
+				"dev/docs=Docs",
+				"dev-2=dev!",
+				"ops=Ops",
+				"ops/docs=Docs",
+				"dev/docs|A|https://a.com||||",
+				"dev|B|https://b.com||||",
+				"dev-2|C|https://c.com||||",
+				"ops/docs|D|https://d.com||||",
+			},
+		},
+		{
+			name:  "traversal in folder names",
+			input: `<DL><DT><H3>..</H3><DL><DT><H3>../../etc</H3><DL><DT><A HREF="https://x.com">x</A></DL></DL></DL>`,
+			want: []string{
+				"folder-5ec1f7e7=..",
+				"folder-5ec1f7e7/etc=../../etc",
+				"folder-5ec1f7e7/etc|x|https://x.com||||",
+			},
+		},
+		{
+			name:  "truncated file",
+			input: `<DL><DT><H3>Dev</H3><DL><DT><A HREF="https://a.com">A</A><DT><A HREF="https://b.com`,
+			want: []string{
+				"dev=Dev",
+				"dev|A|https://a.com||||",
+			},
+		},
+		{
+			name:  "not bookmarks",
+			input: "just some text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir, err := ParseNetscape(strings.NewReader(tt.input), "imported")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got, want := summary(dir), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestNetscapeRoundTrip(t *testing.T) {
+
+	// 书签文件不保存 slug，导入时按文件夹名称重新生成
+	dir := &Directory{
+		Categories: []Category{
+			{Slug: "dev-ops", Name: "Dev & Ops"},
+			{Slug: "dev-ops/docs", Name: "Docs"},
+			{Slug: "tools", Name: "Tools"},
+		},
+		Entries: []Entry{
+			{Category: "dev-ops", Name: `A "quoted" <name>`, Url: "https://a.com/?x=1&y=2", Description: "desc <b>", Tags: []string{"x", "y"},
+				Icon: "https://a.com/i.png", CreateTime: time.Unix(1700000000, 0)},
+			{Category: "dev-ops/docs", Name: "B", Url: "https://b.com"},
+			{Category: "tools", Name: "C", Url: "https://c.com"},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteNetscape(&buf, dir, "My <links>"); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+
+	parsed, err := ParseNetscape(strings.NewReader(exported), "imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"dev-ops=Dev & Ops",
+		"dev-ops/docs=Docs",
+		"tools=Tools",
+		`dev-ops|A "quoted" <name>|https://a.com/?x=1&y=2|desc <b>|x,y|https://a.com/i.png|1700000000`,
+		"dev-ops/docs|B|https://b.com||||",
+		"tools|C|https://c.com||||",
+	}, "\n")
+	if got := summary(parsed); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s\nexported:\n%s", got, want, exported)
+	}
+}
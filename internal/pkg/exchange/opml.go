@@ -0,0 +1,186 @@
+package exchange
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated,omitempty"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+// opmlOutline OPML 节点，slug、description、sort、icon 为本站扩展属性，用于原样导回
+type opmlOutline struct {
+	Text        string        `xml:"text,attr"`
+	Title       string        `xml:"title,attr,omitempty"`
+	Type        string        `xml:"type,attr,omitempty"`
+	Url         string        `xml:"url,attr,omitempty"`
+	HtmlUrl     string        `xml:"htmlUrl,attr,omitempty"`
+	XmlUrl      string        `xml:"xmlUrl,attr,omitempty"`
+	Category    string        `xml:"category,attr,omitempty"`
+	Created     string        `xml:"created,attr,omitempty"`
+	Slug        string        `xml:"slug,attr,omitempty"`
+	Description string        `xml:"description,attr,omitempty"`
+	Icon        string        `xml:"icon,attr,omitempty"`
+	Sort        string        `xml:"sort,attr,omitempty"`
+	Outlines    []opmlOutline `xml:"outline"`
+}
+
+// link 节点的链接地址，订阅源优先使用网站地址
+func (o opmlOutline) link() string {
+	for _, v := range []string{o.HtmlUrl, o.Url, o.XmlUrl} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (o opmlOutline) name() string {
+	if v := strings.TrimSpace(o.Title); v != "" {
+		return v
+	}
+	return strings.TrimSpace(o.Text)
+}
+
+// ParseOpml 解析 OPML，带链接的节点为链接，其余有子节点的节点为分类（可多级），
+// 顶层链接归入 rootCategory。category 属性按 OPML 2.0 约定视为逗号分隔的标签
+func ParseOpml(r io.Reader, rootCategory string) (*Directory, error) {
+
+	var doc opmlDocument
+	decoder := xml.NewDecoder(r)
+	decoder.CharsetReader = charset.NewReaderLabel
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	dir := &Directory{Fields: fieldSet(FieldName, FieldUrl, FieldDescription, FieldTags, FieldIcon, FieldSort, FieldCreateTime)}
+	parseOutlines(dir, doc.Body, "")
+	fillRootCategory(dir, rootCategory)
+
+	return dir, nil
+}
+
+func parseOutlines(dir *Directory, outlines []opmlOutline, parent string) {
+
+	for _, o := range outlines {
+
+		if href := o.link(); href != "" {
+			entry := Entry{
+				Category:    parent,
+				Name:        o.name(),
+				Url:         href,
+				Description: strings.TrimSpace(o.Description),
+				Icon:        strings.TrimSpace(o.Icon),
+			}
+			if entry.Name == "" {
+				entry.Name = href
+			}
+			for _, t := range splitList(o.Category) {
+				entry.Tags = append(entry.Tags, strings.Trim(t, "/"))
+			}
+			entry.Sort, _ = strconv.Atoi(o.Sort)
+			if t, err := time.Parse(time.RFC1123Z, o.Created); err == nil {
+				entry.CreateTime = t
+			} else if t, err := time.Parse(time.RFC1123, o.Created); err == nil {
+				entry.CreateTime = t
+			}
+			dir.Entries = append(dir.Entries, entry)
+			continue
+		}
+
+		if len(o.Outlines) == 0 {
+			continue
+		}
+
+		name := o.name()
+		c := Category{Name: name, Description: strings.TrimSpace(o.Description)}
+		c.Sort, _ = strconv.Atoi(o.Sort)
+		if slug := strings.Trim(o.Slug, "/"); slug != "" {
+			c.Slug = slug
+		} else {
+			c.Slug = uniqueChildSlug(dir, parent, name)
+		}
+		dir.AddCategory(c)
+
+		parseOutlines(dir, o.Outlines, c.Slug)
+	}
+}
+
+// WriteOpml 导出为 OPML 2.0，分类为嵌套节点，链接为 type="link" 的节点
+func WriteOpml(w io.Writer, dir *Directory, title string) error {
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   title,
+		Created: time.Now().Format(time.RFC1123Z),
+		Body:    opmlFolder(dir, categoryTree(dir), ""),
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func opmlFolder(dir *Directory, children map[string][]string, slug string) []opmlOutline {
+
+	var outlines []opmlOutline
+
+	for _, e := range dir.CategoryEntries(slug) {
+		o := opmlOutline{
+			Text:        e.Name,
+			Title:       e.Name,
+			Type:        "link",
+			Url:         e.Url,
+			Category:    strings.Join(e.Tags, ","),
+			Description: e.Description,
+			Icon:        e.Icon,
+		}
+		if e.Sort != 0 {
+			o.Sort = strconv.Itoa(e.Sort)
+		}
+		if !e.CreateTime.IsZero() {
+			o.Created = e.CreateTime.Format(time.RFC1123Z)
+		}
+		outlines = append(outlines, o)
+	}
+
+	for _, child := range children[slug] {
+		name := dir.CategoryName(child)
+		o := opmlOutline{
+			Text:     name,
+			Title:    name,
+			Slug:     child,
+			Outlines: opmlFolder(dir, children, child),
+		}
+		for _, c := range dir.Categories {
+			if c.Slug == child {
+				o.Description = c.Description
+				if c.Sort != 0 {
+					o.Sort = strconv.Itoa(c.Sort)
+				}
+			}
+		}
+		outlines = append(outlines, o)
+	}
+
+	return outlines
+}
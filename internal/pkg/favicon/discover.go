@@ -0,0 +1,208 @@
+package favicon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// candidate 图标候选地址，size 为声明的尺寸，未知时为 0
+type candidate struct {
+	url  string
+	size int
+}
+
+// discover 按页面中的 link rel 图标、manifest 图标、/favicon.ico 的顺序列出候选地址，
+// 同一来源中优先选择不小于目标尺寸的最小图标
+func (r *Resolver) discover(ctx context.Context, pageUrl string) ([]string, error) {
+
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	var icons, manifest []candidate
+
+	resp, err := r.get(ctx, pageUrl, "text/html,application/xhtml+xml")
+	if err == nil {
+		// 以跳转后的地址解析相对路径
+		if resp.Request != nil && resp.Request.URL != nil {
+			base = resp.Request.URL
+		}
+		var manifestUrl string
+		icons, manifestUrl = parseIconLinks(io.LimitReader(resp.Body, maxPageBytes), base)
+		resp.Body.Close()
+
+		if manifestUrl != "" {
+			manifest = r.manifestIcons(ctx, manifestUrl)
+		}
+	}
+
+	var list []string
+	for _, group := range [][]candidate{icons, manifest} {
+		r.sortCandidates(group)
+		for _, c := range group {
+			list = append(list, c.url)
+		}
+	}
+	list = append(list, (&url.URL{Scheme: base.Scheme, Host: base.Host, Path: "/favicon.ico"}).String())
+
+	return dedupe(list), nil
+}
+
+// parseIconLinks 解析页面 head 中的图标链接及 manifest 地址
+func parseIconLinks(body io.Reader, base *url.URL) ([]candidate, string) {
+
+	var icons []candidate
+	manifest := ""
+
+	z := html.NewTokenizer(body)
+	for {
+		tt := z.Next()
+		switch tt {
+		case html.ErrorToken:
+			return icons, manifest
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "head" {
+				return icons, manifest
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) == "body" {
+				return icons, manifest
+			}
+			if string(name) != "link" || !hasAttr {
+				continue
+			}
+
+			attrs := make(map[string]string)
+			for {
+				key, val, more := z.TagAttr()
+				attrs[strings.ToLower(string(key))] = string(val)
+				if !more {
+					break
+				}
+			}
+
+			href := resolve(base, attrs["href"])
+			if href == "" {
+				continue
+			}
+
+			rels := strings.Fields(strings.ToLower(attrs["rel"]))
+			for _, rel := range rels {
+				switch rel {
+				case "icon", "apple-touch-icon", "apple-touch-icon-precomposed":
+					if strings.Contains(attrs["type"], "svg") || strings.HasSuffix(strings.ToLower(href), ".svg") {
+						continue // 无法用标准库栅格化
+					}
+					icons = append(icons, candidate{url: href, size: parseSizes(attrs["sizes"])})
+				case "manifest":
+					manifest = href
+				}
+			}
+		}
+	}
+}
+
+// manifestIcons 读取 Web App Manifest 中的图标
+func (r *Resolver) manifestIcons(ctx context.Context, manifestUrl string) []candidate {
+
+	resp, err := r.get(ctx, manifestUrl, "application/manifest+json,application/json")
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+
+	var manifest struct {
+		Icons []struct {
+			Src   string `json:"src"`
+			Sizes string `json:"sizes"`
+			Type  string `json:"type"`
+		} `json:"icons"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxPageBytes)).Decode(&manifest); err != nil {
+		return nil
+	}
+
+	base := resp.Request.URL
+	var icons []candidate
+	for _, v := range manifest.Icons {
+		if strings.Contains(v.Type, "svg") {
+			continue
+		}
+		if href := resolve(base, v.Src); href != "" {
+			icons = append(icons, candidate{url: href, size: parseSizes(v.Sizes)})
+		}
+	}
+
+	return icons
+}
+
+// sortCandidates 不小于目标尺寸的按从小到大，其余按从大到小，未声明尺寸的排最后
+func (r *Resolver) sortCandidates(list []candidate) {
+
+	rank := func(c candidate) (int, int) {
+		switch {
+		case c.size >= r.opts.Size:
+			return 0, c.size
+		case c.size > 0:
+			return 1, -c.size
+		}
+		return 2, 0
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		gi, si := rank(list[i])
+		gj, sj := rank(list[j])
+		if gi != gj {
+			return gi < gj
+		}
+		return si < sj
+	})
+}
+
+// parseSizes 解析 "16x16 32x32" 形式的尺寸，返回最大的边长
+func parseSizes(s string) int {
+	size := 0
+	for _, v := range strings.Fields(strings.ToLower(s)) {
+		w, _, ok := strings.Cut(v, "x")
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil && n > size {
+			size = n
+		}
+	}
+	return size
+}
+
+func resolve(base *url.URL, href string) string {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "data:") {
+		return ""
+	}
+	u, err := base.Parse(href)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+func dedupe(list []string) []string {
+	seen := make(map[string]bool)
+	var out []string
+	for _, v := range list {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,209 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+)
+
+var (
+	errUnsupported = errors.New("不支持的图片格式")
+	errTooLarge    = errors.New("图片尺寸过大")
+)
+
+// maxDimension 解码前检查的最大边长，避免声明了超大尺寸的图片耗尽内存
+const maxDimension = 2048
+
+// decode 解码 PNG、JPEG、GIF 及 ICO 图片，ICO 取最大的一张
+func decode(data []byte) (image.Image, error) {
+
+	if isIco(data) {
+		return decodeIco(data)
+	}
+
+	return decodeImage(data)
+}
+
+// decodeImage 先读取尺寸，超过 maxDimension 时不解码
+func decodeImage(data []byte) (image.Image, error) {
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupported
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > maxDimension || cfg.Height > maxDimension {
+		return nil, errTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, errUnsupported
+	}
+
+	return img, nil
+}
+
+// normalize 把图片缩放为 size×size 的 PNG，保持比例并居中，空白处透明
+func normalize(img image.Image, size int) ([]byte, error) {
+
+	b := img.Bounds()
+	if b.Dx() <= 0 || b.Dy() <= 0 {
+		return nil, errUnsupported
+	}
+
+	w, h := size, size
+	if b.Dx() > b.Dy() {
+		h = max(1, size*b.Dy()/b.Dx())
+	} else if b.Dy() > b.Dx() {
+		w = max(1, size*b.Dx()/b.Dy())
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, size, size))
+	offset := image.Pt((size-w)/2, (size-h)/2)
+	scaled := resize(img, w, h)
+	draw.Draw(dst, scaled.Bounds().Add(offset), scaled, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, dst); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// resize 缩小时按区域平均，放大时取最近像素
+func resize(src image.Image, w, h int) *image.NRGBA {
+
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			// 按透明度加权，避免透明像素的颜色渗入边缘
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			var c color.NRGBA
+			if a > 0 {
+				c = color.NRGBA{
+					R: uint8(r / a >> 8),
+					G: uint8(g / a >> 8),
+					B: uint8(bl / a >> 8),
+					A: uint8(a / n >> 8),
+				}
+			}
+			dst.SetNRGBA(x, y, c)
+		}
+	}
+
+	return dst
+}
+
+func isIco(data []byte) bool {
+	return len(data) >= 6 && binary.LittleEndian.Uint16(data[0:]) == 0 && binary.LittleEndian.Uint16(data[2:]) == 1
+}
+
+// decodeIco 解码 ICO，支持内嵌 PNG 以及 24/32 位 BMP
+func decodeIco(data []byte) (image.Image, error) {
+
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	if count == 0 || len(data) < 6+16*count {
+		return nil, errUnsupported
+	}
+
+	// 选择尺寸最大的一张，0 表示 256
+	best, bestSize := -1, 0
+	for i := 0; i < count; i++ {
+		size := int(data[6+16*i])
+		if size == 0 {
+			size = 256
+		}
+		if size > bestSize {
+			best, bestSize = i, size
+		}
+	}
+
+	entry := data[6+16*best:]
+	length := int(binary.LittleEndian.Uint32(entry[8:]))
+	offset := int(binary.LittleEndian.Uint32(entry[12:]))
+	if offset < 0 || length <= 0 || offset+length > len(data) {
+		return nil, errUnsupported
+	}
+	img := data[offset : offset+length]
+
+	if bytes.HasPrefix(img, []byte("\x89PNG")) {
+		return decodeImage(img)
+	}
+
+	return decodeIcoBmp(img)
+}
+
+// decodeIcoBmp 解码 ICO 中不带文件头的 BMP，高度为图像与掩码高度之和。
+// 头部长度和像素数据都来自远程文件，使用前检查是否超出数据范围
+func decodeIcoBmp(data []byte) (image.Image, error) {
+
+	if len(data) < 40 {
+		return nil, errUnsupported
+	}
+
+	headerSize := int(binary.LittleEndian.Uint32(data[0:]))
+	width := int(int32(binary.LittleEndian.Uint32(data[4:])))
+	height := int(int32(binary.LittleEndian.Uint32(data[8:]))) / 2
+	bpp := int(binary.LittleEndian.Uint16(data[14:]))
+
+	if width <= 0 || height <= 0 || width > 512 || height > 512 || (bpp != 32 && bpp != 24) {
+		return nil, errUnsupported
+	}
+
+	if headerSize < 40 || headerSize > len(data) {
+		return nil, errUnsupported
+	}
+
+	stride := (width*bpp/8 + 3) &^ 3
+	maskStride := ((width + 31) / 32) * 4
+	pixels := data[headerSize:]
+	if len(pixels) < stride*height {
+		return nil, errUnsupported
+	}
+	mask := pixels[stride*height:]
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		row := pixels[(height-1-y)*stride:] // 行从下往上存储
+		for x := 0; x < width; x++ {
+			p := row[x*bpp/8:]
+			c := color.NRGBA{R: p[2], G: p[1], B: p[0], A: 255}
+			if bpp == 32 {
+				c.A = p[3]
+			} else if len(mask) >= maskStride*height {
+				m := mask[(height-1-y)*maskStride+x/8]
+				if m&(0x80>>(x%8)) != 0 {
+					c.A = 0
+				}
+			}
+			img.SetNRGBA(x, y, c)
+		}
+	}
+
+	return img, nil
+}
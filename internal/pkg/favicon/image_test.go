@@ -0,0 +1,71 @@
+package favicon
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// ico 构造只有一张图片的 ICO 文件
+func ico(img []byte) []byte {
+	head := make([]byte, 22)
+	binary.LittleEndian.PutUint16(head[2:], 1)
+	binary.LittleEndian.PutUint16(head[4:], 1)
+	head[6] = 16
+	binary.LittleEndian.PutUint32(head[14:], uint32(len(img)))
+	binary.LittleEndian.PutUint32(head[18:], 22)
+	return append(head, img...)
+}
+
+// bmp 构造 ICO 内的 BMP 头部，后面跟 extra 字节的像素数据
+func bmp(headerSize uint32, width, height int32, bpp uint16, extra int) []byte {
+	data := make([]byte, 40+extra)
+	binary.LittleEndian.PutUint32(data[0:], headerSize)
+	binary.LittleEndian.PutUint32(data[4:], uint32(width))
+	binary.LittleEndian.PutUint32(data[8:], uint32(height*2))
+	binary.LittleEndian.PutUint16(data[14:], bpp)
+	return data
+}
+
+func pngOf(w, h int) []byte {
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, w, h)))
+	return buf.Bytes()
+}
+
+func TestDecode(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+		size    int
+	}{
+		{"png", pngOf(16, 16), nil, 16},
+		{"oversized png", pngOf(maxDimension+1, 1), errTooLarge, 0},
+		{"not an image", []byte("<html></html>"), errUnsupported, 0},
+		{"ico bmp 32", ico(bmp(40, 2, 2, 32, 2*2*4)), nil, 2},
+		{"ico bmp 24 with mask", ico(bmp(40, 2, 2, 24, 2*8+2*4)), nil, 2},
+		{"ico png", ico(pngOf(8, 8)), nil, 8},
+		{"ico oversized png", ico(pngOf(1, maxDimension+1)), errTooLarge, 0},
+		{"header size beyond data", ico(bmp(0xffffff, 2, 2, 32, 16)), errUnsupported, 0},
+		{"header size too small", ico(bmp(4, 2, 2, 32, 16)), errUnsupported, 0},
+		{"truncated pixels", ico(bmp(40, 16, 16, 32, 8)), errUnsupported, 0},
+		{"entry beyond data", func() []byte { d := ico(bmp(40, 2, 2, 32, 16)); return d[:30] }(), errUnsupported, 0},
+		{"unsupported bpp", ico(bmp(40, 2, 2, 8, 16)), errUnsupported, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := decode(tt.data)
+			if err != tt.wantErr {
+				t.Fatalf("decode() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && img.Bounds().Dx() != tt.size {
+				t.Errorf("decode() width = %d, want %d", img.Bounds().Dx(), tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package favicon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxPageBytes  = 512 << 10 // 读取网页和 manifest 的上限
+	maxImageBytes = 1 << 20   // 图标文件大小上限
+	missRetry     = 24 * time.Hour
+)
+
+// ErrNotFound 没有找到可用的图标，或离线模式下缓存中没有
+var ErrNotFound = errors.New("图标不存在")
+
+// Options 图标解析选项，零值字段使用默认值
+type Options struct {
+	Dir        string        // 缓存目录
+	Client     *http.Client  // 发送请求的客户端，测试时可替换
+	Size       int           // 输出图标的边长，默认 64
+	Timeout    time.Duration // 单次请求超时，默认 10 秒
+	MaxAge     time.Duration // 缓存有效期，过期后重新获取，失败时继续使用旧图标，默认 30 天
+	MaxEntries int           // 缓存图标数量上限，超出时删除最久未访问的，默认 2000
+	Offline    bool          // 离线模式，只使用缓存，不发送任何请求
+	UserAgent  string
+}
+
+// Resolver 图标解析器，缓存为 Dir 下的 <key>.png，获取失败的记录为 <key>.miss，一天内不再重试
+type Resolver struct {
+	opts   Options
+	client *http.Client
+
+	mx       sync.Mutex
+	accessed map[string]time.Time // key -> 最近访问时间，用于淘汰
+	calls    map[string]*call     // 正在获取的 key，合并并发请求
+}
+
+type call struct {
+	wg   sync.WaitGroup
+	file string
+	err  error
+}
+
+// New 创建图标解析器
+func New(opts Options) (*Resolver, error) {
+
+	if opts.Dir == "" {
+		return nil, errors.New("缺少缓存目录")
+	}
+	if opts.Size <= 0 {
+		opts.Size = 64
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 30 * 24 * time.Hour
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = 2000
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "Mozilla/5.0 (compatible; mdnav-favicon)"
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	if opts.Client != nil {
+		copied := *opts.Client
+		if copied.Timeout == 0 {
+			copied.Timeout = opts.Timeout
+		}
+		client = &copied
+	}
+
+	r := &Resolver{
+		opts:     opts,
+		client:   client,
+		accessed: make(map[string]time.Time),
+		calls:    make(map[string]*call),
+	}
+
+	// 以文件修改时间作为初始访问时间
+	entries, err := os.ReadDir(opts.Dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if key, ok := strings.CutSuffix(e.Name(), ".png"); ok {
+			if info, err := e.Info(); err == nil {
+				r.accessed[key] = info.ModTime()
+			}
+		}
+	}
+	r.Evict()
+
+	return r, nil
+}
+
+// Site 获取网站图标，key 一般为域名，pageUrl 为用于发现图标的网页地址，返回缓存文件路径
+func (r *Resolver) Site(ctx context.Context, key, pageUrl string) (string, error) {
+	return r.resolve(ctx, key, func(ctx context.Context) ([]string, error) {
+		return r.discover(ctx, pageUrl)
+	})
+}
+
+// Remote 通过缓存代理指定的远程图标，返回缓存文件路径
+func (r *Resolver) Remote(ctx context.Context, key, iconUrl string) (string, error) {
+	return r.resolve(ctx, key, func(ctx context.Context) ([]string, error) {
+		return []string{iconUrl}, nil
+	})
+}
+
+func (r *Resolver) resolve(ctx context.Context, key string, candidates func(context.Context) ([]string, error)) (string, error) {
+
+	key = safeKey(key)
+	if key == "" {
+		return "", ErrNotFound
+	}
+
+	file := filepath.Join(r.opts.Dir, key+".png")
+	info, statErr := os.Stat(file)
+	cached := statErr == nil
+
+	if cached && (r.opts.Offline || time.Since(info.ModTime()) < r.opts.MaxAge) {
+		r.touch(key)
+		return file, nil
+	}
+	if r.opts.Offline {
+		return "", ErrNotFound
+	}
+
+	// 最近获取失败过，暂不重试
+	if miss, err := os.Stat(filepath.Join(r.opts.Dir, key+".miss")); err == nil && time.Since(miss.ModTime()) < missRetry {
+		if cached {
+			r.touch(key)
+			return file, nil
+		}
+		return "", ErrNotFound
+	}
+
+	_, err := r.fetchOnce(ctx, key, file, candidates)
+	if err != nil {
+		if cached {
+			r.touch(key)
+			return file, nil // 使用过期的图标
+		}
+		return "", err
+	}
+
+	return file, nil
+}
+
+// fetchOnce 同一个 key 同时只获取一次，其余请求等待结果。获取过程 panic 时转换为错误，
+// 保证等待的请求都能返回，之后的请求可以重新获取
+func (r *Resolver) fetchOnce(ctx context.Context, key, file string, candidates func(context.Context) ([]string, error)) (result string, err error) {
+
+	r.mx.Lock()
+	if c, ok := r.calls[key]; ok {
+		r.mx.Unlock()
+		c.wg.Wait()
+		return c.file, c.err
+	}
+	c := &call{}
+	c.wg.Add(1)
+	r.calls[key] = c
+	r.mx.Unlock()
+
+	defer func() {
+		if p := recover(); p != nil {
+			c.file, c.err = "", fmt.Errorf("获取图标出错：%v", p)
+		}
+		c.wg.Done()
+
+		r.mx.Lock()
+		delete(r.calls, key)
+		r.mx.Unlock()
+
+		result, err = c.file, c.err
+	}()
+
+	// 获取结果会被其他请求共用，不随当前请求取消
+	c.file, c.err = r.fetch(context.WithoutCancel(ctx), key, file, candidates)
+
+	return c.file, c.err
+}
+
+func (r *Resolver) fetch(ctx context.Context, key, file string, candidates func(context.Context) ([]string, error)) (string, error) {
+
+	missFile := filepath.Join(r.opts.Dir, key+".miss")
+
+	list, err := candidates(ctx)
+	if err == nil {
+		for _, u := range list {
+			data, err := r.download(ctx, u)
+			if err != nil {
+				continue
+			}
+			img, err := decode(data)
+			if err != nil {
+				continue
+			}
+			out, err := normalize(img, r.opts.Size)
+			if err != nil {
+				continue
+			}
+
+			tmp := file + ".tmp"
+			if err := os.WriteFile(tmp, out, 0644); err != nil {
+				return "", err
+			}
+			if err := os.Rename(tmp, file); err != nil {
+				return "", err
+			}
+			os.Remove(missFile)
+
+			r.touch(key)
+			r.Evict()
+			return file, nil
+		}
+	}
+
+	os.WriteFile(missFile, nil, 0644)
+	return "", ErrNotFound
+}
+
+func (r *Resolver) download(ctx context.Context, u string) ([]byte, error) {
+
+	resp, err := r.get(ctx, u, "image/*")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxImageBytes {
+		return nil, errors.New("图标文件过大")
+	}
+
+	return data, nil
+}
+
+// get 发送 GET 请求，状态码不是 200 时返回错误
+func (r *Resolver) get(ctx context.Context, u, accept string) (*http.Response, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", r.opts.UserAgent)
+	req.Header.Set("Accept", accept)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s 返回 %d", u, resp.StatusCode)
+	}
+
+	return resp, nil
+}
+
+func (r *Resolver) touch(key string) {
+	r.mx.Lock()
+	r.accessed[key] = time.Now()
+	r.mx.Unlock()
+}
+
+// Evict 缓存数量超过 MaxEntries 时删除最久未访问的图标，同时清理过期的失败记录
+func (r *Resolver) Evict() {
+
+	r.mx.Lock()
+	var victims []string
+	if over := len(r.accessed) - r.opts.MaxEntries; over > 0 {
+		keys := make([]string, 0, len(r.accessed))
+		for k := range r.accessed {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return r.accessed[keys[i]].Before(r.accessed[keys[j]])
+		})
+		victims = keys[:over]
+		for _, k := range victims {
+			delete(r.accessed, k)
+		}
+	}
+	r.mx.Unlock()
+
+	for _, k := range victims {
+		os.Remove(filepath.Join(r.opts.Dir, k+".png"))
+	}
+
+	misses, _ := filepath.Glob(filepath.Join(r.opts.Dir, "*.miss"))
+	for _, f := range misses {
+		if info, err := os.Stat(f); err == nil && time.Since(info.ModTime()) >= missRetry {
+			os.Remove(f)
+		}
+	}
+}
+
+// safeKey 只保留域名中合法的字符，避免路径穿越
+func safeKey(key string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(key) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '.', c == '-', c == '_':
+			b.WriteRune(c)
+		case c == ':':
+			b.WriteRune('_') // 端口
+		}
+	}
+	return strings.Trim(b.String(), ".")
+}
@@ -0,0 +1,38 @@
+package favicon
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestFetchOnceRecoversPanic(t *testing.T) {
+
+	r, err := New(Options{Dir: t.TempDir(), Offline: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	boom := func(context.Context) ([]string, error) { panic("boom") }
+	if _, err := r.fetchOnce(context.Background(), "k", "k.png", boom); err == nil {
+		t.Fatal("fetchOnce() error = nil, want recovered panic")
+	}
+
+	// 之后的请求不能卡在上一次的等待上
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.fetchOnce(context.Background(), "k", "k.png", func(context.Context) ([]string, error) {
+			return nil, ErrNotFound
+		})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("fetchOnce() error = nil, want ErrNotFound")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("fetchOnce() blocked after a panic")
+	}
+}
@@ -0,0 +1,241 @@
+package linkcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Options 检查选项，零值字段使用默认值
+type Options struct {
+	Client       *http.Client  // 发送请求的客户端，测试时可替换，默认使用带超时的新客户端
+	Timeout      time.Duration // 单次请求超时，默认 10 秒
+	Concurrency  int           // 总并发数，默认 8
+	PerHost      int           // 同一域名的并发数，默认 2
+	MaxRedirects int           // 最多跟随的跳转次数，默认 10
+	Retries      int           // 网络错误、5xx 和 429 的重试次数，默认 2
+	Backoff      time.Duration // 第一次重试前的等待时间，之后每次加倍，默认 1 秒
+	UserAgent    string
+}
+
+// Result 单个链接的检查结果
+type Result struct {
+	Url        string        `json:"url"`
+	OK         bool          `json:"ok"`
+	StatusCode int           `json:"status_code"`         // 最终响应的状态码，请求失败时为 0
+	Error      string        `json:"error,omitempty"`     // 请求失败的原因
+	FinalUrl   string        `json:"final_url,omitempty"` // 跟随跳转后的地址，没有跳转时为空
+	Redirects  []string      `json:"redirects,omitempty"` // 跳转经过的地址
+	Attempts   int           `json:"attempts"`            // 包括重试在内的请求次数
+	Duration   time.Duration `json:"duration"`            // 最后一次请求耗时
+	CheckedAt  time.Time     `json:"checked_at"`
+}
+
+// Checker 链接检查器，可以并发使用
+type Checker struct {
+	opts   Options
+	client *http.Client
+
+	mx    sync.Mutex
+	hosts map[string]chan struct{} // 每个域名的并发信号量
+}
+
+// New 创建检查器
+func New(opts Options) *Checker {
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.PerHost <= 0 {
+		opts.PerHost = 2
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = 10
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "Mozilla/5.0 (compatible; mdnav-linkcheck)"
+	}
+
+	client := opts.Client
+	if client == nil {
+		client = &http.Client{}
+	}
+
+	return &Checker{
+		opts:   opts,
+		client: client,
+		hosts:  make(map[string]chan struct{}),
+	}
+}
+
+// CheckAll 并发检查全部链接，同一域名的请求数不超过 PerHost。
+// 先占用域名的名额再占用总并发名额，等待同一域名的请求不会占住总并发，使其他域名无法检查
+func (c *Checker) CheckAll(ctx context.Context, urls []string) []Result {
+
+	results := make([]Result, len(urls))
+	sem := make(chan struct{}, c.opts.Concurrency)
+
+	var wg sync.WaitGroup
+	for i, u := range urls {
+		wg.Add(1)
+		go func(i int, u string) {
+			defer wg.Done()
+			results[i] = c.check(ctx, u, sem)
+		}(i, u)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Check 检查单个链接：先发送 HEAD，服务器不支持 HEAD 时改用 GET，
+// 网络错误、5xx 和 429 按 Backoff 加倍等待后重试
+func (c *Checker) Check(ctx context.Context, rawUrl string) Result {
+	return c.check(ctx, rawUrl, nil)
+}
+
+// check 占用域名的并发名额后，再占用 sem（不为 nil 时）中的名额，然后检查链接
+func (c *Checker) check(ctx context.Context, rawUrl string, sem chan struct{}) Result {
+
+	result := Result{Url: rawUrl}
+
+	u, err := url.Parse(rawUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		result.Error = "url 格式错误"
+		result.CheckedAt = time.Now()
+		return result
+	}
+
+	release := c.acquire(ctx, u.Host)
+	defer release()
+
+	if sem != nil {
+		defer acquireSlot(ctx, sem)()
+	}
+
+	backoff := c.opts.Backoff
+	for attempt := 0; ; attempt++ {
+		result = c.request(ctx, rawUrl)
+		result.Attempts = attempt + 1
+
+		if !retryable(result) || attempt >= c.opts.Retries {
+			return result
+		}
+
+		select {
+		case <-ctx.Done():
+			return result
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Checker) request(ctx context.Context, rawUrl string) Result {
+
+	result := c.do(ctx, http.MethodHead, rawUrl)
+
+	// 部分网站不支持 HEAD 或对 HEAD 返回错误状态
+	if result.Error != "" || result.StatusCode == http.StatusMethodNotAllowed ||
+		result.StatusCode == http.StatusNotImplemented || result.StatusCode == http.StatusForbidden ||
+		result.StatusCode == http.StatusNotFound {
+		if get := c.do(ctx, http.MethodGet, rawUrl); get.Error == "" || result.Error != "" {
+			result = get
+		}
+	}
+
+	return result
+}
+
+func (c *Checker) do(ctx context.Context, method, rawUrl string) Result {
+
+	result := Result{Url: rawUrl, CheckedAt: time.Now()}
+
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, rawUrl, nil)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	req.Header.Set("User-Agent", c.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+	// 复制客户端以记录本次请求的跳转，不修改调用方传入的客户端
+	client := *c.client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) > c.opts.MaxRedirects {
+			return fmt.Errorf("跳转次数超过 %d 次", c.opts.MaxRedirects)
+		}
+		result.Redirects = append(result.Redirects, req.URL.String())
+		return nil
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	result.Duration = time.Since(start)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		result.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	if method == http.MethodGet {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	}
+
+	result.StatusCode = resp.StatusCode
+	result.OK = resp.StatusCode >= 200 && resp.StatusCode < 400
+	if len(result.Redirects) > 0 {
+		result.FinalUrl = resp.Request.URL.String()
+	}
+
+	return result
+}
+
+// acquire 占用域名的一个并发名额，返回释放函数
+func (c *Checker) acquire(ctx context.Context, host string) func() {
+
+	c.mx.Lock()
+	sem, ok := c.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, c.opts.PerHost)
+		c.hosts[host] = sem
+	}
+	c.mx.Unlock()
+
+	return acquireSlot(ctx, sem)
+}
+
+// acquireSlot 占用信号量的一个名额，返回释放函数。ctx 结束时不再等待，返回空的释放函数
+func acquireSlot(ctx context.Context, sem chan struct{}) func() {
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }
+	case <-ctx.Done():
+		return func() {}
+	}
+}
+
+func retryable(r Result) bool {
+	return r.StatusCode == 0 || r.StatusCode == http.StatusTooManyRequests || r.StatusCode >= 500
+}
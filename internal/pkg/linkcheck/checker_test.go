@@ -0,0 +1,208 @@
+package linkcheck
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheck(t *testing.T) {
+
+	var mx sync.Mutex
+	hits := make(map[string]int) // 路径 -> 请求次数
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/r1", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/r2", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/r2", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	mux.HandleFunc("/head-404", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	// /flaky/<状态码> 前两次请求返回该状态码，之后返回 200，HEAD 和 GET 分别计数
+	mux.HandleFunc("/flaky/", func(w http.ResponseWriter, r *http.Request) {
+		mx.Lock()
+		hits[r.Method+r.URL.Path]++
+		n := hits[r.Method+r.URL.Path]
+		mx.Unlock()
+		if n <= 2 {
+			code := http.StatusServiceUnavailable
+			if strings.HasSuffix(r.URL.Path, "/429") {
+				code = http.StatusTooManyRequests
+			}
+			w.WriteHeader(code)
+		}
+	})
+	mux.HandleFunc("/down", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		opts       Options
+		ok         bool
+		status     int
+		attempts   int
+		redirects  int
+		finalPath  string
+		errSubstr  string
+		minElapsed time.Duration // 重试等待的最短总时间
+	}{
+		{name: "ok", path: "/ok", ok: true, status: 200, attempts: 1},
+		{name: "not found", path: "/missing", status: 404, attempts: 1},
+		{name: "redirect chain", path: "/r1", ok: true, status: 200, attempts: 1, redirects: 2, finalPath: "/ok"},
+		{name: "too many redirects", path: "/loop", opts: Options{MaxRedirects: 3}, attempts: 1, redirects: 3, errSubstr: "跳转次数超过 3 次"},
+		{name: "head not allowed", path: "/no-head", ok: true, status: 200, attempts: 1},
+		{name: "head not found", path: "/head-404", ok: true, status: 200, attempts: 1},
+		{name: "retry 5xx", path: "/flaky/503", opts: Options{Retries: 2}, ok: true, status: 200, attempts: 3, minElapsed: 30 * time.Millisecond},
+		{name: "retry 429", path: "/flaky/429", opts: Options{Retries: 2}, ok: true, status: 200, attempts: 3, minElapsed: 30 * time.Millisecond},
+		{name: "retries exhausted", path: "/down", opts: Options{Retries: 2}, status: 500, attempts: 3, minElapsed: 30 * time.Millisecond},
+		{name: "no retries", path: "/down", status: 500, attempts: 1},
+		{name: "timeout", path: "/slow", opts: Options{Timeout: 50 * time.Millisecond}, attempts: 1, errSubstr: "deadline exceeded"},
+		{name: "invalid url", path: "", errSubstr: "url 格式错误"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			opts := tt.opts
+			opts.Backoff = 10 * time.Millisecond
+			checker := New(opts)
+
+			target := server.URL + tt.path
+			if tt.path == "" {
+				target = "ftp://example.com"
+			}
+
+			start := time.Now()
+			result := checker.Check(context.Background(), target)
+			elapsed := time.Since(start)
+
+			if result.OK != tt.ok || result.StatusCode != tt.status || result.Attempts != tt.attempts {
+				t.Errorf("ok=%v status=%d attempts=%d, want ok=%v status=%d attempts=%d (error %q)",
+					result.OK, result.StatusCode, result.Attempts, tt.ok, tt.status, tt.attempts, result.Error)
+			}
+			if len(result.Redirects) != tt.redirects {
+				t.Errorf("redirects = %v, want %d", result.Redirects, tt.redirects)
+			}
+			if tt.finalPath != "" && result.FinalUrl != server.URL+tt.finalPath {
+				t.Errorf("final url = %q, want %q", result.FinalUrl, server.URL+tt.finalPath)
+			}
+			if !strings.Contains(result.Error, tt.errSubstr) || (tt.errSubstr == "" && result.Error != "") {
+				t.Errorf("error = %q, want %q", result.Error, tt.errSubstr)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("elapsed = %v, want at least %v", elapsed, tt.minElapsed)
+			}
+			if tt.name == "timeout" && elapsed > time.Second {
+				t.Errorf("elapsed = %v, timeout not applied", elapsed)
+			}
+		})
+	}
+}
+
+func TestCheckAllPerHost(t *testing.T) {
+
+	var inFlight, peak int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 10)
+	for i := range urls {
+		urls[i] = server.URL + "/" + string(rune('a'+i))
+	}
+
+	results := New(Options{Concurrency: 8, PerHost: 2}).CheckAll(context.Background(), urls)
+
+	for i, r := range results {
+		if !r.OK || r.Url != urls[i] {
+			t.Errorf("result %d = %+v, want ok for %s", i, r, urls[i])
+		}
+	}
+	if peak > 2 {
+		t.Errorf("peak concurrency per host = %d, want at most 2", peak)
+	}
+}
+
+func TestCheckAllOtherHostsNotBlocked(t *testing.T) {
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+
+	fastDone := make(chan struct{})
+	var once sync.Once
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		once.Do(func() { close(fastDone) })
+	}))
+	defer fast.Close()
+
+	// 同一域名的链接远多于总并发数，等待域名名额的请求不应占住总并发
+	urls := []string{slow.URL + "/a", fast.URL}
+	for i := 0; i < 20; i++ {
+		urls = append(urls, slow.URL+"/"+string(rune('b'+i)))
+	}
+
+	done := make(chan []Result)
+	go func() {
+		done <- New(Options{Concurrency: 2, PerHost: 1}).CheckAll(context.Background(), urls)
+	}()
+
+	select {
+	case <-fastDone:
+	case <-time.After(2 * time.Second):
+		t.Error("request to another host was blocked by a busy host")
+	}
+
+	close(release)
+	results := <-done
+	if r := results[1]; !r.OK {
+		t.Errorf("fast host result = %+v", r)
+	}
+}
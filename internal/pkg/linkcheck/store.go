@@ -0,0 +1,188 @@
+package linkcheck
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 链接状态
+const (
+	StatusUnknown = "unknown" // 尚未检查
+	StatusOK      = "ok"
+	StatusBroken  = "broken"
+)
+
+// Health 单个链接的健康状况及检查历史
+type Health struct {
+	Url       string    `json:"url"`
+	Status    string    `json:"status"`
+	Last      Result    `json:"last"`                // 最近一次检查结果
+	LastOK    time.Time `json:"last_ok,omitzero"`    // 最近一次正常的时间
+	DownSince time.Time `json:"down_since,omitzero"` // 连续失败的开始时间，正常时为空
+	Failures  int       `json:"failures"`            // 连续失败次数
+	History   []Result  `json:"history"`             // 最近的检查结果，最新的在最后
+}
+
+// DownFor 截至 now 已连续失败的时长，正常时为 0
+func (h Health) DownFor(now time.Time) time.Duration {
+	if h.DownSince.IsZero() {
+		return 0
+	}
+	return now.Sub(h.DownSince)
+}
+
+// Store 以 JSON 文件保存的检查结果
+type Store struct {
+	path    string
+	history int // 每个链接保留的历史条数
+
+	mx    sync.RWMutex
+	links map[string]*Health
+}
+
+// OpenStore 打开结果文件，文件不存在时创建空的存储，history 为每个链接保留的历史条数
+func OpenStore(path string, history int) (*Store, error) {
+
+	if history <= 0 {
+		history = 20
+	}
+
+	s := &Store{path: path, history: history, links: make(map[string]*Health)}
+
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*Health
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, err
+	}
+	for _, h := range list {
+		s.links[h.Url] = h
+	}
+
+	return s, nil
+}
+
+// Record 记录一次检查结果
+func (s *Store) Record(r Result) {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	h, ok := s.links[r.Url]
+	if !ok {
+		h = &Health{Url: r.Url}
+		s.links[r.Url] = h
+	}
+
+	h.Last = r
+	h.History = append(h.History, r)
+	if len(h.History) > s.history {
+		h.History = h.History[len(h.History)-s.history:]
+	}
+
+	if r.OK {
+		h.Status = StatusOK
+		h.LastOK = r.CheckedAt
+		h.DownSince = time.Time{}
+		h.Failures = 0
+		return
+	}
+
+	h.Status = StatusBroken
+	h.Failures++
+	if h.DownSince.IsZero() {
+		h.DownSince = r.CheckedAt
+	}
+}
+
+// Get 获取链接的健康状况
+func (s *Store) Get(url string) (Health, bool) {
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	h, ok := s.links[url]
+	if !ok {
+		return Health{Url: url, Status: StatusUnknown}, false
+	}
+
+	return *h, true
+}
+
+// All 全部链接，按 url 排序
+func (s *Store) All() []Health {
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	list := make([]Health, 0, len(s.links))
+	for _, h := range s.links {
+		list = append(list, *h)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Url < list[j].Url })
+
+	return list
+}
+
+// Statuses url -> 状态
+func (s *Store) Statuses() map[string]string {
+
+	s.mx.RLock()
+	defer s.mx.RUnlock()
+
+	statuses := make(map[string]string, len(s.links))
+	for url, h := range s.links {
+		statuses[url] = h.Status
+	}
+
+	return statuses
+}
+
+// Prune 删除不在 keep 中的链接，返回删除的数量
+func (s *Store) Prune(keep map[string]bool) int {
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	n := 0
+	for url := range s.links {
+		if !keep[url] {
+			delete(s.links, url)
+			n++
+		}
+	}
+
+	return n
+}
+
+// Save 写入结果文件，先写临时文件再替换
+func (s *Store) Save() error {
+
+	list := s.All()
+
+	content, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.path)
+}
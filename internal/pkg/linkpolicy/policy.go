@@ -0,0 +1,154 @@
+// Package linkpolicy 站外链接的统一处理：rel、新窗口打开、UTM 参数、域名白名单和黑名单，
+// 同时用于 Markdown 正文（goldmark AST 转换）和模板中的文档链接
+package linkpolicy
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Policy 链接策略，对应配置文件的 link_policy 部分
+type Policy struct {
+	Rel           []string          `mapstructure:"rel"`            // 站外链接的 rel
+	NewWindow     bool              `mapstructure:"new_window"`     // 站外链接在新窗口打开
+	UTM           map[string]string `mapstructure:"utm"`            // 追加到站外链接的参数，链接中已有的同名参数不覆盖
+	Allow         []string          `mapstructure:"allow"`          // 不为空时只有这些域名（含子域名）的站外链接可以点击
+	Block         []string          `mapstructure:"block"`          // 这些域名（含子域名）的链接不可点击
+	InternalHosts []string          `mapstructure:"internal_hosts"` // 视为站内的域名，不做任何处理
+	LazyImages    bool              `mapstructure:"lazy_images"`    // 正文图片延迟加载
+}
+
+// DefaultPolicy 未配置时的链接策略
+func DefaultPolicy() Policy {
+	return Policy{
+		Rel:        []string{"noopener", "noreferrer", "nofollow"},
+		NewWindow:  true,
+		LazyImages: true,
+	}
+}
+
+var (
+	mx     sync.RWMutex
+	policy = DefaultPolicy()
+)
+
+// Configure 设置链接策略，之后渲染的正文和模板都使用新的策略
+func Configure(p Policy) {
+	for _, list := range [][]string{p.Allow, p.Block, p.InternalHosts} {
+		for i, v := range list {
+			list[i] = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(v), "."))
+		}
+	}
+
+	mx.Lock()
+	defer mx.Unlock()
+	policy = p
+}
+
+// Current 当前的链接策略
+func Current() Policy {
+	mx.RLock()
+	defer mx.RUnlock()
+	return policy
+}
+
+// Link 按策略处理后的链接
+type Link struct {
+	Url      string // 追加 UTM 参数后的地址，不可点击时为空
+	External bool   // 是否为站外链接
+	Blocked  bool   // 被白名单或黑名单拦截
+}
+
+// Resolve 按策略处理链接。站内链接（相对地址、internal_hosts）和非 http(s) 链接原样返回
+func (p Policy) Resolve(raw string) Link {
+
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return Link{Url: raw}
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme == "javascript" || scheme == "vbscript" || scheme == "data" {
+		return Link{Blocked: true}
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == "" || (scheme != "http" && scheme != "https" && scheme != "") || matchHost(host, p.InternalHosts) {
+		return Link{Url: raw}
+	}
+
+	if matchHost(host, p.Block) || (len(p.Allow) > 0 && !matchHost(host, p.Allow)) {
+		return Link{External: true, Blocked: true}
+	}
+
+	if len(p.UTM) > 0 {
+		query := u.Query()
+		for k, v := range p.UTM {
+			if !query.Has(k) {
+				query.Set(k, v)
+			}
+		}
+		u.RawQuery = query.Encode()
+		raw = u.String()
+	}
+
+	return Link{Url: raw, External: true}
+}
+
+// Attrs 站外链接需要的 target 和 rel 属性
+func (p Policy) Attrs(l Link) map[string]string {
+
+	attrs := make(map[string]string)
+	if !l.External || l.Blocked {
+		return attrs
+	}
+
+	if p.NewWindow {
+		attrs["target"] = "_blank"
+	}
+	if len(p.Rel) > 0 {
+		attrs["rel"] = strings.Join(p.Rel, " ")
+	}
+
+	return attrs
+}
+
+// HTMLAttrs 拼接好的属性，用于直接输出 HTML 的地方（如短代码），前面带一个空格
+func (p Policy) HTMLAttrs(l Link) string {
+
+	var b strings.Builder
+	attrs := p.Attrs(l)
+	for _, name := range []string{"target", "rel"} {
+		if v, ok := attrs[name]; ok {
+			b.WriteString(" " + name + `="` + html.EscapeString(v) + `"`)
+		}
+	}
+
+	return b.String()
+}
+
+// matchHost host 是否为列表中的域名或其子域名
+func matchHost(host string, list []string) bool {
+	for _, v := range list {
+		if host == v || strings.HasSuffix(host, "."+v) {
+			return true
+		}
+	}
+	return false
+}
+
+// FuncMap 模板函数：outUrl 返回处理后的地址（不可点击时为空），linkAttrs 返回 target 和 rel 属性，
+// 例如 <a href="{{ outUrl .Url }}"{{ linkAttrs .Url }}>
+var FuncMap = template.FuncMap{
+	"outUrl": func(raw string) string {
+		return Current().Resolve(raw).Url
+	},
+	"linkAttrs": func(raw string) template.HTMLAttr {
+		p := Current()
+		return template.HTMLAttr(p.HTMLAttrs(p.Resolve(raw)))
+	},
+}
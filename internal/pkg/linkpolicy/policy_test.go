@@ -0,0 +1,98 @@
+package linkpolicy
+
+import "testing"
+
+func TestResolve(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		policy Policy
+		raw    string
+		want   Link
+	}{
+		{name: "external", raw: "https://example.com/a", want: Link{Url: "https://example.com/a", External: true}},
+		{name: "relative", raw: "/article/a", want: Link{Url: "/article/a"}},
+		{name: "anchor", raw: "#top", want: Link{Url: "#top"}},
+		{name: "mailto", raw: "mailto:a@example.com", want: Link{Url: "mailto:a@example.com"}},
+		{name: "empty", raw: "  ", want: Link{}},
+		{name: "javascript", raw: "JavaScript:alert(1)", want: Link{Blocked: true}},
+		{name: "data", raw: "data:text/html,x", want: Link{Blocked: true}},
+		{name: "vbscript", raw: " vbscript:x", want: Link{Blocked: true}},
+
+		// 黑名单
+		{name: "blocked host", policy: Policy{Block: []string{"bad.com"}}, raw: "https://bad.com/x",
+			want: Link{External: true, Blocked: true}},
+		{name: "blocked subdomain", policy: Policy{Block: []string{"bad.com"}}, raw: "http://www.BAD.com:8080/x",
+			want: Link{External: true, Blocked: true}},
+		{name: "blocked scheme relative", policy: Policy{Block: []string{"bad.com"}}, raw: "//bad.com/x",
+			want: Link{External: true, Blocked: true}},
+		{name: "lookalike not blocked", policy: Policy{Block: []string{"bad.com"}}, raw: "https://notbad.com/x",
+			want: Link{Url: "https://notbad.com/x", External: true}},
+		{name: "block wins over allow", policy: Policy{Allow: []string{"example.com"}, Block: []string{"ads.example.com"}},
+			raw: "https://ads.example.com", want: Link{External: true, Blocked: true}},
+
+		// 白名单
+		{name: "allowed host", policy: Policy{Allow: []string{"example.com"}}, raw: "https://docs.example.com/a",
+			want: Link{Url: "https://docs.example.com/a", External: true}},
+		{name: "not in allow list", policy: Policy{Allow: []string{"example.com"}}, raw: "https://example.org/a",
+			want: Link{External: true, Blocked: true}},
+		{name: "allow list ignores relative", policy: Policy{Allow: []string{"example.com"}}, raw: "/tag/ai",
+			want: Link{Url: "/tag/ai"}},
+		{name: "internal host", policy: Policy{Allow: []string{"example.com"}, InternalHosts: []string{"mysite.com"}},
+			raw: "https://mysite.com/a", want: Link{Url: "https://mysite.com/a"}},
+
+		// UTM 参数
+		{name: "utm", policy: Policy{UTM: map[string]string{"utm_source": "nav"}}, raw: "https://example.com/a?x=1",
+			want: Link{Url: "https://example.com/a?utm_source=nav&x=1", External: true}},
+		{name: "utm keeps existing", policy: Policy{UTM: map[string]string{"utm_source": "nav"}}, raw: "https://example.com/?utm_source=me",
+			want: Link{Url: "https://example.com/?utm_source=me", External: true}},
+		{name: "utm skips internal", policy: Policy{UTM: map[string]string{"utm_source": "nav"}}, raw: "/article/a",
+			want: Link{Url: "/article/a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Resolve(tt.raw); got != tt.want {
+				t.Errorf("Resolve(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigureNormalizesHosts(t *testing.T) {
+
+	defer Configure(DefaultPolicy())
+
+	Configure(Policy{Block: []string{" .Bad.COM "}, Allow: []string{"Example.com"}, InternalHosts: []string{".MySite.com"}})
+
+	p := Current()
+	for raw, want := range map[string]Link{
+		"https://sub.bad.com":    {External: true, Blocked: true},
+		"https://example.com/":   {Url: "https://example.com/", External: true},
+		"https://www.mysite.com": {Url: "https://www.mysite.com"},
+	} {
+		if got := p.Resolve(raw); got != want {
+			t.Errorf("Resolve(%q) = %+v, want %+v", raw, got, want)
+		}
+	}
+}
+
+func TestHTMLAttrs(t *testing.T) {
+
+	p := Policy{Rel: []string{"noopener", "nofollow"}, NewWindow: true}
+
+	tests := []struct {
+		link Link
+		want string
+	}{
+		{link: Link{Url: "https://example.com", External: true}, want: ` target="_blank" rel="noopener nofollow"`},
+		{link: Link{Url: "/article/a"}, want: ""},
+		{link: Link{External: true, Blocked: true}, want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := p.HTMLAttrs(tt.link); got != tt.want {
+			t.Errorf("HTMLAttrs(%+v) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package linkpolicy
+
+import (
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+)
+
+type transformer struct{}
+
+// Transformer 按当前策略处理正文中的链接和图片：站外链接加 target、rel 和 UTM 参数，
+// 被拦截的链接只保留文字，图片加 loading="lazy"
+var Transformer parser.ASTTransformer = transformer{}
+
+func (transformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+
+	p := Current()
+	source := reader.Source()
+
+	var blocked, autolinks []ast.Node
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+
+		switch v := n.(type) {
+		case *ast.Link:
+			l := p.Resolve(string(v.Destination))
+			if l.Blocked {
+				blocked = append(blocked, v)
+				return ast.WalkContinue, nil
+			}
+			v.Destination = []byte(l.Url)
+			for name, value := range p.Attrs(l) {
+				v.SetAttributeString(name, []byte(value))
+			}
+		case *ast.AutoLink:
+			if v.AutoLinkType != ast.AutoLinkURL {
+				return ast.WalkContinue, nil
+			}
+			if p.Resolve(string(v.URL(source))).Blocked {
+				blocked = append(blocked, v)
+			} else {
+				autolinks = append(autolinks, v)
+			}
+		case *ast.Image:
+			if p.LazyImages {
+				v.SetAttributeString("loading", []byte("lazy"))
+			}
+		}
+
+		return ast.WalkContinue, nil
+	})
+
+	for _, n := range blocked {
+		unlink(n, source)
+	}
+	for _, n := range autolinks {
+		relink(p, n.(*ast.AutoLink), source)
+	}
+}
+
+// relink 把自动链接换成普通链接，以便追加 UTM 参数，链接文字保持原样
+func relink(p Policy, n *ast.AutoLink, source []byte) {
+
+	parent := n.Parent()
+	if parent == nil {
+		return
+	}
+
+	l := p.Resolve(string(n.URL(source)))
+	link := ast.NewLink()
+	link.Destination = []byte(l.Url)
+	link.AppendChild(link, ast.NewString(n.Label(source)))
+	for name, value := range p.Attrs(l) {
+		link.SetAttributeString(name, []byte(value))
+	}
+
+	parent.ReplaceChild(parent, n, link)
+}
+
+// unlink 用链接的文字替换链接
+func unlink(n ast.Node, source []byte) {
+
+	parent := n.Parent()
+	if parent == nil {
+		return
+	}
+
+	if auto, ok := n.(*ast.AutoLink); ok {
+		parent.ReplaceChild(parent, n, ast.NewString(auto.Label(source)))
+		return
+	}
+
+	for child := n.FirstChild(); child != nil; {
+		next := child.NextSibling()
+		parent.InsertBefore(parent, n, child)
+		child = next
+	}
+	parent.RemoveChild(parent, n)
+}
@@ -0,0 +1,549 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"mdnav/internal/utils"
+)
+
+// 数据文件名，分类目录下的一个数据文件可以包含多个链接
+var dataFileNames = map[string]bool{
+	"links.yaml": true,
+	"links.yml":  true,
+	"links.json": true,
+	"links.csv":  true,
+}
+
+// CSV 表头，顺序即导出顺序
+var csvColumns = []string{"slug", "name", "url", "description", "keywords", "tags", "icon", "image", "sort", "published", "is_show", "featured", "layout", "create_time", "pin_until", "publish_at", "expire_at"}
+
+// LinkRecord 数据文件中的一条链接记录
+type LinkRecord struct {
+	Slug        string    `yaml:"slug,omitempty" json:"slug,omitempty"` // 为空时根据url生成
+	Name        string    `yaml:"name" json:"name"`
+	Url         string    `yaml:"url" json:"url"`
+	Description string    `yaml:"description,omitempty" json:"description,omitempty"`
+	Keywords    string    `yaml:"keywords,omitempty" json:"keywords,omitempty"`
+	Tags        []string  `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Icon        string    `yaml:"icon,omitempty" json:"icon,omitempty"`
+	Image       string    `yaml:"image,omitempty" json:"image,omitempty"`
+	Sort        int       `yaml:"sort,omitempty" json:"sort,omitempty"`
+	Published   *bool     `yaml:"published,omitempty" json:"published,omitempty"` // 为空表示发布
+	IsShow      *bool     `yaml:"is_show,omitempty" json:"is_show,omitempty"`     // 为空表示显示
+	Featured    bool      `yaml:"featured,omitempty" json:"featured,omitempty"`
+	Layout      string    `yaml:"layout,omitempty" json:"layout,omitempty"`
+	CreateTime  time.Time `yaml:"create_time,omitempty" json:"create_time,omitzero"`
+	PinUntil    time.Time `yaml:"pin_until,omitempty" json:"pin_until,omitzero"`
+	PublishAt   time.Time `yaml:"publish_at,omitempty" json:"publish_at,omitzero"`
+	ExpireAt    time.Time `yaml:"expire_at,omitempty" json:"expire_at,omitzero"`
+	Line        int       `yaml:"-" json:"-"` // 记录在文件中的行号，解析时填写
+}
+
+// IsDataFile 判断文件名是否为链接数据文件
+func IsDataFile(name string) bool {
+	return dataFileNames[strings.ToLower(name)]
+}
+
+// Markdown 转换为与 md 文件相同的结构
+func (r LinkRecord) Markdown() Markdown {
+	return Markdown{
+		Name:        r.Name,
+		Keywords:    r.Keywords,
+		Description: r.Description,
+		Published:   r.Published == nil || *r.Published,
+		IsShow:      r.IsShow == nil || *r.IsShow,
+		Sort:        r.Sort,
+		Icon:        r.Icon,
+		Url:         r.Url,
+		Tags:        r.Tags,
+		Image:       r.Image,
+		CreateTime:  r.CreateTime,
+		Slug:        r.Slug,
+		Featured:    r.Featured,
+		PinUntil:    r.PinUntil,
+		PublishAt:   r.PublishAt,
+		ExpireAt:    r.ExpireAt,
+		Layout:      r.Layout,
+	}
+}
+
+// ErrInvalidRecordSlug 记录的 slug 不是单段的合法 slug
+var ErrInvalidRecordSlug = errors.New("slug 只能包含小写字母、数字和 -")
+
+// CheckRecordSlug 校验记录的 slug，slug 与所在分类拼接成文档 slug，不能包含 / 或 ..；为空时根据 url 生成
+func CheckRecordSlug(slug string) error {
+	if slug == "" || utils.Slugify(slug) == slug {
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidRecordSlug, slug)
+}
+
+// ParseDataFile 解析链接数据文件，slug 不合法的记录视为解析错误
+func ParseDataFile(filePath string) ([]LinkRecord, error) {
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	content = bytes.TrimPrefix(content, utf8BOM)
+
+	var records []LinkRecord
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		records, err = parseCsvRecords(content)
+	case ".json":
+		records, err = parseJsonRecords(content)
+	default:
+		records, err = parseYamlRecords(content)
+	}
+
+	if err == nil {
+		for _, r := range records {
+			if err = CheckRecordSlug(r.Slug); err != nil {
+				err = &ParseError{Line: r.Line, Column: 1, Err: err}
+				break
+			}
+		}
+	}
+
+	if err != nil {
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.File = filePath
+		}
+		return nil, err
+	}
+
+	return records, nil
+}
+
+// WriteDataFile 按文件扩展名写入链接数据文件。YAML 文件保留原有的注释、字段顺序和未修改字段的写法，
+// JSON 和 CSV 按记录重新生成
+func WriteDataFile(filePath string, records []LinkRecord) error {
+
+	var buf bytes.Buffer
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		if err := writeCsvRecords(&buf, records); err != nil {
+			return err
+		}
+	case ".json":
+		encoder := json.NewEncoder(&buf)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(records); err != nil {
+			return err
+		}
+	default:
+		original, _ := os.ReadFile(filePath)
+		node, err := mergeYamlRecords(bytes.TrimPrefix(original, utf8BOM), records)
+		if err != nil {
+			return err
+		}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(node); err != nil {
+			return err
+		}
+		encoder.Close()
+	}
+
+	// 先写临时文件再替换，避免监听到写了一半的文件
+	tmp := filePath + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filePath)
+}
+
+// mergeYamlRecords 生成写入的 YAML 节点。记录按 slug（未指定时按 url）对应到原文件中的条目，
+// 对应上的条目保留注释和字段顺序，值未变化的字段保留原来的写法，如纯日期和行内列表
+func mergeYamlRecords(original []byte, records []LinkRecord) (*yaml.Node, error) {
+
+	var list yaml.Node
+	if err := list.Encode(records); err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.SequenceNode {
+		return &list, nil
+	}
+
+	items := make(map[string]*yaml.Node)
+	for _, item := range doc.Content[0].Content {
+		var r LinkRecord
+		if item.Kind != yaml.MappingNode || item.Decode(&r) != nil {
+			continue
+		}
+		if _, ok := items[r.key()]; !ok {
+			items[r.key()] = item
+		}
+	}
+
+	for i, item := range list.Content {
+		if prev, ok := items[records[i].key()]; ok {
+			list.Content[i] = mergeYamlMapping(prev, item)
+		}
+	}
+
+	doc.Content[0].Content = list.Content
+
+	return &doc, nil
+}
+
+// mergeYamlMapping 以原条目为基础写入新的字段值，已清空的字段删除，新增字段追加在末尾
+func mergeYamlMapping(prev, next *yaml.Node) *yaml.Node {
+
+	values := make(map[string]*yaml.Node)
+	for i := 0; i+1 < len(next.Content); i += 2 {
+		values[next.Content[i].Value] = next.Content[i+1]
+	}
+
+	merged := *prev
+	merged.Content = nil
+
+	for i := 0; i+1 < len(prev.Content); i += 2 {
+		key, value := prev.Content[i], prev.Content[i+1]
+		nextValue, ok := values[key.Value]
+		if !ok {
+			continue
+		}
+		if !sameYamlValue(key.Value, value, nextValue) {
+			nextValue.HeadComment, nextValue.LineComment, nextValue.FootComment = value.HeadComment, value.LineComment, value.FootComment
+			value = nextValue
+		}
+		merged.Content = append(merged.Content, key, value)
+		delete(values, key.Value)
+	}
+
+	for i := 0; i+1 < len(next.Content); i += 2 {
+		if _, ok := values[next.Content[i].Value]; ok {
+			merged.Content = append(merged.Content, next.Content[i], next.Content[i+1])
+		}
+	}
+
+	return &merged
+}
+
+// sameYamlValue 两个节点的值是否相同，时间字段按 front matter 的规则解析后比较
+func sameYamlValue(key string, prev, next *yaml.Node) bool {
+
+	if timeKeys[key] && prev.Kind == yaml.ScalarNode {
+		normalized := *prev
+		var a, b time.Time
+		return normalizeTime(&normalized) == nil && normalized.Decode(&a) == nil && next.Decode(&b) == nil && a.Equal(b)
+	}
+
+	var a, b any
+	return prev.Decode(&a) == nil && next.Decode(&b) == nil && reflect.DeepEqual(a, b)
+}
+
+// key 写入时对应原条目使用的标识
+func (r LinkRecord) key() string {
+	if r.Slug != "" {
+		return "slug:" + r.Slug
+	}
+	return "url:" + strings.TrimSpace(r.Url)
+}
+
+func parseYamlRecords(content []byte) ([]LinkRecord, error) {
+
+	fm := &FrontMatter{Format: FormatYaml, Data: content, Line: 1}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fm.yamlError(err)
+	}
+
+	return decodeRecords(fm, &node)
+}
+
+func parseJsonRecords(content []byte) ([]LinkRecord, error) {
+
+	var list []any
+	if err := json.Unmarshal(content, &list); err != nil {
+		return nil, jsonError(content, err, 1)
+	}
+
+	out, err := yaml.Marshal(list)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(out, &node); err != nil {
+		return nil, err
+	}
+
+	records, err := decodeRecords(&FrontMatter{Format: FormatJson, Data: content, Line: 1}, &node)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, line := range jsonItemLines(content) {
+		if i < len(records) {
+			records[i].Line = line
+		}
+	}
+
+	return records, nil
+}
+
+// jsonItemLines JSON 数组中每个元素开始的行号
+func jsonItemLines(content []byte) []int {
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	if _, err := decoder.Token(); err != nil {
+		return nil
+	}
+
+	var lines []int
+	for decoder.More() {
+		offset := int(decoder.InputOffset())
+		start := offset + len(content[offset:]) - len(bytes.TrimLeft(content[offset:], " \t\r\n,"))
+		lines = append(lines, bytes.Count(content[:start], []byte("\n"))+1)
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			break
+		}
+	}
+
+	return lines
+}
+
+// decodeRecords 解析记录列表，时间字段与 front matter 使用相同的格式规则
+func decodeRecords(fm *FrontMatter, node *yaml.Node) ([]LinkRecord, error) {
+
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	root := node.Content[0]
+	if root.Kind != yaml.SequenceNode {
+		return nil, &ParseError{Line: root.Line, Column: 1, Err: errors.New("数据文件必须是链接列表")}
+	}
+
+	for _, item := range root.Content {
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			key, value := item.Content[i], item.Content[i+1]
+			if timeKeys[key.Value] && value.Kind == yaml.ScalarNode {
+				if err := normalizeTime(value); err != nil {
+					return nil, &ParseError{Line: fm.keyLine(key), Column: 1, Err: fmt.Errorf("%s: %w", key.Value, err)}
+				}
+			}
+		}
+	}
+
+	var records []LinkRecord
+	if err := root.Decode(&records); err != nil {
+		return nil, fm.yamlError(err)
+	}
+
+	if fm.Format == FormatYaml {
+		for i := range records {
+			records[i].Line = root.Content[i].Line + fm.Line - 1
+		}
+	}
+
+	return records, nil
+}
+
+func parseCsvRecords(content []byte) ([]LinkRecord, error) {
+
+	reader := csv.NewReader(bytes.NewReader(content))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["url"]; !ok {
+		return nil, &ParseError{Line: 1, Column: 1, Err: errors.New("CSV 表头缺少 url 列")}
+	}
+
+	var records []LinkRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, csvError(err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		get := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		record := LinkRecord{
+			Slug:        get("slug"),
+			Name:        get("name"),
+			Url:         get("url"),
+			Description: get("description"),
+			Keywords:    get("keywords"),
+			Tags:        SplitTags(get("tags")),
+			Icon:        get("icon"),
+			Image:       get("image"),
+			Line:        line,
+		}
+
+		if v := get("sort"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, &ParseError{Line: line, Column: columns["sort"] + 1, Err: fmt.Errorf("sort 必须为整数: %q", v)}
+			}
+			record.Sort = n
+		}
+
+		if v := get("published"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, &ParseError{Line: line, Column: columns["published"] + 1, Err: fmt.Errorf("published 必须为 true 或 false: %q", v)}
+			}
+			record.Published = &b
+		}
+
+		if v := get("is_show"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, &ParseError{Line: line, Column: columns["is_show"] + 1, Err: fmt.Errorf("is_show 必须为 true 或 false: %q", v)}
+			}
+			record.IsShow = &b
+		}
+
+		if v := get("featured"); v != "" {
+			record.Featured, _ = strconv.ParseBool(v)
+		}
+
+		record.Layout = get("layout")
+
+		for name, field := range map[string]*time.Time{
+			"create_time": &record.CreateTime,
+			"pin_until":   &record.PinUntil,
+			"publish_at":  &record.PublishAt,
+			"expire_at":   &record.ExpireAt,
+		} {
+			if v := get(name); v != "" {
+				t, err := ParseTime(v)
+				if err != nil {
+					return nil, &ParseError{Line: line, Column: columns[name] + 1, Err: fmt.Errorf("%s: %w", name, err)}
+				}
+				*field = t
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+func writeCsvRecords(w io.Writer, records []LinkRecord) error {
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvColumns); err != nil {
+		return err
+	}
+
+	formatBool := func(b *bool) string {
+		if b == nil {
+			return ""
+		}
+		return strconv.FormatBool(*b)
+	}
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, r := range records {
+		sort := ""
+		if r.Sort != 0 {
+			sort = strconv.Itoa(r.Sort)
+		}
+		featured := ""
+		if r.Featured {
+			featured = "true"
+		}
+
+		row := []string{r.Slug, r.Name, r.Url, r.Description, r.Keywords, strings.Join(r.Tags, ";"), r.Icon, r.Image, sort,
+			formatBool(r.Published), formatBool(r.IsShow), featured, r.Layout,
+			formatTime(r.CreateTime), formatTime(r.PinUntil), formatTime(r.PublishAt), formatTime(r.ExpireAt)}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func csvError(err error) error {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return &ParseError{Line: csvErr.Line, Column: csvErr.Column, Err: csvErr.Err}
+	}
+	return err
+}
+
+// SplitTags 拆分以 ; | 或 , 分隔的标签
+func SplitTags(s string) []string {
+
+	var tags []string
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ';' || r == '|' || r == ',' || r == '，'
+	}) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+
+	return tags
+}
+
+// ParseTime 按 front matter 相同的规则解析时间
+func ParseTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无法解析日期 %q", value)
+}
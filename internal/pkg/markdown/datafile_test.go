@@ -0,0 +1,206 @@
+package markdown
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseDataFile(t *testing.T) {
+
+	date := func(s string) time.Time {
+		v, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		return v
+	}
+	no := false
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    []LinkRecord
+		errLine int // 大于 0 时期望在该行出错
+	}{
+		{
+			name: "yaml",
+			file: "links.yaml",
+			content: "# 常用链接\n- name: A\n  url: https://a.com\n  create_time: 2024-05-01\n" +
+				"  publish_at: 2024-06-01 08:00\n  is_show: false\n  layout: table\n",
+			want: []LinkRecord{{Name: "A", Url: "https://a.com", CreateTime: date("2024-05-01 00:00"),
+				PublishAt: date("2024-06-01 08:00"), IsShow: &no, Layout: "table"}},
+		},
+		{
+			name:    "json",
+			file:    "links.json",
+			content: `[{"name": "A", "url": "https://a.com", "pin_until": "2024-05-01", "expire_at": "2024/07/01"}]`,
+			want:    []LinkRecord{{Name: "A", Url: "https://a.com", PinUntil: date("2024-05-01 00:00"), ExpireAt: date("2024-07-01 00:00")}},
+		},
+		{
+			name:    "csv",
+			file:    "links.csv",
+			content: "\xEF\xBB\xBFname,url,tags,is_show,publish_at\nA,https://a.com,a;b,false,2024-05-01\n",
+			want:    []LinkRecord{{Name: "A", Url: "https://a.com", Tags: []string{"a", "b"}, IsShow: &no, PublishAt: date("2024-05-01 00:00")}},
+		},
+		{
+			name:    "empty",
+			file:    "links.yaml",
+			content: "",
+		},
+		{
+			name:    "yaml invalid date",
+			file:    "links.yaml",
+			content: "- name: A\n  url: https://a.com\n  expire_at: soon\n",
+			errLine: 3,
+		},
+		{
+			name:    "yaml not a list",
+			file:    "links.yaml",
+			content: "name: A\n",
+			errLine: 1,
+		},
+		{
+			name:    "json syntax error",
+			file:    "links.json",
+			content: "[\n  {\"name\": }\n]",
+			errLine: 2,
+		},
+		{
+			name:    "csv missing url column",
+			file:    "links.csv",
+			content: "name\nA\n",
+			errLine: 1,
+		},
+		{
+			name:    "yaml slug with path",
+			file:    "links.yaml",
+			content: "- name: A\n  url: https://a.com\n- name: B\n  slug: ../other/x\n  url: https://b.com\n",
+			errLine: 3,
+		},
+		{
+			name:    "json nested slug",
+			file:    "links.json",
+			content: "[\n  {\"name\": \"A\", \"slug\": \"a/b\", \"url\": \"https://a.com\"}\n]",
+			errLine: 2,
+		},
+		{
+			name:    "csv slug not slugified",
+			file:    "links.csv",
+			content: "slug,name,url\nGitHub,A,https://a.com\n",
+			errLine: 2,
+		},
+		{
+			name:    "csv invalid is_show",
+			file:    "links.csv",
+			content: "name,url,is_show\nA,https://a.com,maybe\n",
+			errLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			file := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(file, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := ParseDataFile(file)
+			if tt.errLine > 0 {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("err = %v, want *ParseError", err)
+				}
+				if parseErr.Line != tt.errLine || parseErr.File != file {
+					t.Errorf("error at %s:%d, want %s:%d (%v)", parseErr.File, parseErr.Line, file, tt.errLine, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d records, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if !sameRecord(got[i], tt.want[i]) {
+					t.Errorf("record %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDataFileKeepsYamlComments(t *testing.T) {
+
+	original := "# 常用链接\n" +
+		"- name: A # 首页\n" +
+		"  url: https://a.com\n" +
+		"  tags: [x, y]\n" +
+		"  create_time: 2024-05-01\n" +
+		"# 备用\n" +
+		"- slug: b\n" +
+		"  url: https://b.com\n" +
+		"  name: B\n"
+
+	file := filepath.Join(t.TempDir(), "links.yaml")
+	if err := os.WriteFile(file, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := ParseDataFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records[0].Name = "A2"
+	records[1].Description = "bbb"
+	records = append(records, LinkRecord{Name: "C", Url: "https://c.com"})
+
+	if err := WriteDataFile(file, records); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _ := os.ReadFile(file)
+	want := "# 常用链接\n" +
+		"- name: A2 # 首页\n" +
+		"  url: https://a.com\n" +
+		"  tags: [x, y]\n" +
+		"  create_time: 2024-05-01\n" +
+		"# 备用\n" +
+		"- slug: b\n" +
+		"  url: https://b.com\n" +
+		"  name: B\n" +
+		"  description: bbb\n" +
+		"- name: C\n" +
+		"  url: https://c.com\n"
+	if string(got) != want {
+		t.Errorf("file =\n%s\nwant\n%s", got, want)
+	}
+
+	reparsed, err := ParseDataFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reparsed) != len(records) || !sameRecord(reparsed[0], records[0]) || !sameRecord(reparsed[1], records[1]) {
+		t.Errorf("reparsed = %+v, want %+v", reparsed, records)
+	}
+}
+
+func sameRecord(a, b LinkRecord) bool {
+	boolValue := func(p *bool) string {
+		if p == nil {
+			return "nil"
+		}
+		if *p {
+			return "true"
+		}
+		return "false"
+	}
+	return a.Slug == b.Slug && a.Name == b.Name && a.Url == b.Url && a.Description == b.Description &&
+		a.Layout == b.Layout && len(a.Tags) == len(b.Tags) &&
+		boolValue(a.Published) == boolValue(b.Published) && boolValue(a.IsShow) == boolValue(b.IsShow) &&
+		a.CreateTime.Equal(b.CreateTime) && a.PinUntil.Equal(b.PinUntil) &&
+		a.PublishAt.Equal(b.PublishAt) && a.ExpireAt.Equal(b.ExpireAt)
+}
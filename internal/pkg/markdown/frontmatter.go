@@ -0,0 +1,347 @@
+package markdown
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatterFormat front matter 格式
+type FrontMatterFormat string
+
+const (
+	FormatYaml FrontMatterFormat = "yaml" // --- 包裹
+	FormatToml FrontMatterFormat = "toml" // +++ 包裹
+	FormatJson FrontMatterFormat = "json" // 以 { 开头的 JSON 对象
+)
+
+// FrontMatter 拆分出的 front matter
+type FrontMatter struct {
+	Format FrontMatterFormat
+	Data   []byte
+	Line   int // Data 第一行在文件中的行号
+
+	lines map[string]int // TOML/JSON 各字段在原文中首次出现的行号，按需生成
+}
+
+// Strings 字符串列表，兼容只写一个值的写法，如 menu: footer
+type Strings []string
+
+func (s *Strings) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		if value.Value == "" {
+			*s = nil
+			return nil
+		}
+		*s = Strings{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// ParseError 带行号的解析错误
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// 时间字段支持的格式，没有时区时按本地时区处理
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"2006/01/02 15:04:05",
+	"2006/01/02 15:04",
+	"2006/01/02",
+}
+
+// 需要按日期解析的字段
+var timeKeys = map[string]bool{
+	"create_time": true,
+	"pin_until":   true,
+	"publish_at":  true,
+	"expire_at":   true,
+}
+
+var (
+	utf8BOM       = []byte{0xEF, 0xBB, 0xBF}
+	yamlLineRegex = regexp.MustCompile(`line (\d+)`)
+	keyLineRegex  = regexp.MustCompile(`(?m)^[ \t]*"?([^"\s:=]+)"?[ \t]*[:=]`)
+)
+
+// SplitFrontMatter 拆分 front matter 和正文，支持 YAML(---)、TOML(+++) 和 JSON({...})，
+// 兼容 UTF-8 BOM 和 CRLF 换行，没有 front matter 时返回 nil。
+// 正文以 { 开头但不像 JSON 对象（如 {{< 短代码 >}}）时视为没有 front matter
+func SplitFrontMatter(content []byte) (fm *FrontMatter, mdContent string, err error) {
+
+	content = bytes.TrimPrefix(content, utf8BOM)
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+
+	switch {
+	case hasDelimiter(content, "---"):
+		return splitDelimited(content, "---", FormatYaml)
+	case hasDelimiter(content, "+++"):
+		return splitDelimited(content, "+++", FormatToml)
+	case isJsonObject(content):
+		return splitJson(content)
+	}
+
+	return nil, string(content), nil
+}
+
+// hasDelimiter 第一行是否为分隔符
+func hasDelimiter(content []byte, delim string) bool {
+	line, _, _ := bytes.Cut(content, []byte("\n"))
+	return strings.TrimRight(string(line), " \t") == delim
+}
+
+// isJsonObject 是否以 JSON 对象开头：{ 之后跳过空白，紧跟 " 或 }
+func isJsonObject(content []byte) bool {
+
+	if !bytes.HasPrefix(content, []byte("{")) {
+		return false
+	}
+
+	rest := bytes.TrimLeft(content[1:], " \t\n")
+	return len(rest) > 0 && (rest[0] == '"' || rest[0] == '}')
+}
+
+func splitDelimited(content []byte, delim string, format FrontMatterFormat) (*FrontMatter, string, error) {
+
+	lines := bytes.SplitAfter(content, []byte("\n"))
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(string(lines[i]), " \t\n") != delim {
+			continue
+		}
+		fm := &FrontMatter{
+			Format: format,
+			Data:   bytes.Join(lines[1:i], nil),
+			Line:   2,
+		}
+		return fm, string(bytes.Join(lines[i+1:], nil)), nil
+	}
+
+	return nil, "", &ParseError{Line: 1, Column: 1, Err: fmt.Errorf("front matter 缺少结束分隔符 %s", delim)}
+}
+
+func splitJson(content []byte) (*FrontMatter, string, error) {
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, "", jsonError(content, err, 1)
+	}
+
+	end := int(decoder.InputOffset())
+	body := strings.TrimPrefix(string(content[end:]), "\n")
+
+	return &FrontMatter{Format: FormatJson, Data: content[:end], Line: 1}, body, nil
+}
+
+// Decode 把 front matter 解析到 markdownDoc，返回顶层字段所在的行号
+func (fm *FrontMatter) Decode(markdownDoc *Markdown) (keyLines map[string]int, err error) {
+
+	node, err := fm.node()
+	if err != nil {
+		return nil, err
+	}
+
+	keyLines = make(map[string]int)
+
+	if len(node.Content) == 0 {
+		return keyLines, nil
+	}
+
+	root := node.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, &ParseError{Line: fm.Line, Column: 1, Err: errors.New("front matter 必须是键值对")}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		keyLines[key.Value] = fm.keyLine(key)
+
+		if timeKeys[key.Value] && value.Kind == yaml.ScalarNode {
+			if err := normalizeTime(value); err != nil {
+				return nil, &ParseError{Line: keyLines[key.Value], Column: 1, Err: fmt.Errorf("%s: %w", key.Value, err)}
+			}
+		}
+	}
+
+	if err := node.Decode(markdownDoc); err != nil {
+		return nil, fm.yamlError(err)
+	}
+
+	return keyLines, nil
+}
+
+// node 把各格式的 front matter 统一转换为 yaml.Node
+func (fm *FrontMatter) node() (*yaml.Node, error) {
+
+	data := fm.Data
+
+	switch fm.Format {
+	case FormatToml:
+		var m map[string]any
+		if err := toml.Unmarshal(fm.Data, &m); err != nil {
+			var decodeErr *toml.DecodeError
+			if errors.As(err, &decodeErr) {
+				row, col := decodeErr.Position()
+				return nil, &ParseError{Line: row + fm.Line - 1, Column: col, Err: err}
+			}
+			return nil, &ParseError{Line: fm.Line, Column: 1, Err: err}
+		}
+		out, err := yaml.Marshal(plainValue(m))
+		if err != nil {
+			return nil, err
+		}
+		data = out
+	case FormatJson:
+		var m map[string]any
+		if err := json.Unmarshal(fm.Data, &m); err != nil {
+			return nil, jsonError(fm.Data, err, fm.Line)
+		}
+		out, err := yaml.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		data = out
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fm.yamlError(err)
+	}
+
+	return &node, nil
+}
+
+// keyLine 顶层字段在原文件中的行号，TOML/JSON 转换后行号会变化，按字段名在原文中查找
+func (fm *FrontMatter) keyLine(key *yaml.Node) int {
+
+	if fm.Format == FormatYaml {
+		return key.Line + fm.Line - 1
+	}
+
+	if fm.lines == nil {
+		fm.lines = make(map[string]int)
+		for _, loc := range keyLineRegex.FindAllSubmatchIndex(fm.Data, -1) {
+			name := string(fm.Data[loc[2]:loc[3]])
+			if _, ok := fm.lines[name]; !ok {
+				fm.lines[name] = bytes.Count(fm.Data[:loc[0]], []byte("\n")) + fm.Line
+			}
+		}
+	}
+
+	if line, ok := fm.lines[key.Value]; ok {
+		return line
+	}
+
+	return fm.Line
+}
+
+// yamlError yaml 错误信息中的行号是相对 front matter 的，转换为文件行号
+func (fm *FrontMatter) yamlError(err error) error {
+
+	line := fm.Line
+	if fm.Format == FormatYaml {
+		if m := yamlLineRegex.FindStringSubmatch(err.Error()); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			line = n + fm.Line - 1
+		}
+	}
+
+	return &ParseError{Line: line, Column: 1, Err: err}
+}
+
+func jsonError(data []byte, err error, firstLine int) error {
+
+	var offset int64 = -1
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+
+	if offset < 0 || offset > int64(len(data)) {
+		return &ParseError{Line: firstLine, Column: 1, Err: err}
+	}
+
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + firstLine
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+
+	return &ParseError{Line: line, Column: column, Err: err}
+}
+
+// normalizeTime 把字符串或纯日期转换为 yaml 时间戳
+func normalizeTime(node *yaml.Node) error {
+
+	value := strings.TrimSpace(node.Value)
+	if value == "" {
+		node.Tag = "!!null"
+		return nil
+	}
+
+	t, err := ParseTime(value)
+	if err != nil {
+		return err
+	}
+
+	node.Value = t.Format(time.RFC3339Nano)
+	node.Tag = "!!timestamp"
+	node.Style = 0
+	return nil
+}
+
+// plainValue 把 TOML 本地日期时间类型转换为字符串，便于统一按日期格式解析
+func plainValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			val[k] = plainValue(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = plainValue(item)
+		}
+		return val
+	case toml.LocalDate, toml.LocalDateTime, toml.LocalTime:
+		return val.(fmt.Stringer).String()
+	}
+	return v
+}
@@ -0,0 +1,221 @@
+package markdown
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		content string
+		format  FrontMatterFormat // 为空表示没有 front matter
+		data    string
+		body    string
+		wantErr bool
+	}{
+		{
+			name:    "yaml",
+			content: "---\nname: a\n---\nbody\n",
+			format:  FormatYaml,
+			data:    "name: a\n",
+			body:    "body\n",
+		},
+		{
+			name:    "toml",
+			content: "+++\nname = \"a\"\n+++\nbody",
+			format:  FormatToml,
+			data:    "name = \"a\"\n",
+			body:    "body",
+		},
+		{
+			name:    "json",
+			content: "{\n  \"name\": \"a\"\n}\nbody",
+			format:  FormatJson,
+			data:    "{\n  \"name\": \"a\"\n}",
+			body:    "body",
+		},
+		{
+			name:    "empty json object",
+			content: "{ }\nbody",
+			format:  FormatJson,
+			data:    "{ }",
+			body:    "body",
+		},
+		{
+			name:    "bom",
+			content: "\xEF\xBB\xBF---\nname: a\n---\nbody",
+			format:  FormatYaml,
+			data:    "name: a\n",
+			body:    "body",
+		},
+		{
+			name:    "crlf",
+			content: "---\r\nname: a\r\n---\r\nbody\r\n",
+			format:  FormatYaml,
+			data:    "name: a\n",
+			body:    "body\n",
+		},
+		{
+			name:    "delimiter with trailing spaces",
+			content: "--- \nname: a\n---\t\nbody",
+			format:  FormatYaml,
+			data:    "name: a\n",
+			body:    "body",
+		},
+		{
+			name:    "no front matter",
+			content: "# title\n",
+			body:    "# title\n",
+		},
+		{
+			name:    "shortcode body",
+			content: "{{< link \"a\" >}}\n",
+			body:    "{{< link \"a\" >}}\n",
+		},
+		{
+			name:    "brace text body",
+			content: "{a} b\n",
+			body:    "{a} b\n",
+		},
+		{
+			name:    "lone brace",
+			content: "{",
+			body:    "{",
+		},
+		{
+			name:    "unterminated yaml",
+			content: "---\nname: a\nbody",
+			wantErr: true,
+		},
+		{
+			name:    "invalid json",
+			content: "{\"name\": }\nbody",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			fm, body, err := SplitFrontMatter([]byte(tt.content))
+			if tt.wantErr {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("err = %v, want *ParseError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if tt.format == "" {
+				if fm != nil {
+					t.Fatalf("front matter = %+v, want nil", fm)
+				}
+			} else {
+				if fm == nil {
+					t.Fatal("front matter = nil")
+				}
+				if fm.Format != tt.format || string(fm.Data) != tt.data {
+					t.Errorf("front matter = %s %q, want %s %q", fm.Format, fm.Data, tt.format, tt.data)
+				}
+			}
+
+			if body != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+
+	local := func(s string) time.Time {
+		v, _ := time.ParseInLocation("2006-01-02 15:04", s, time.Local)
+		return v
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		want     Markdown
+		keyLines map[string]int
+		errLine  int // 大于 0 时期望在该行出错
+	}{
+		{
+			name:     "yaml",
+			content:  "---\nname: A\nslug: a\npublish_at: 2024-05-01\n---\nbody",
+			want:     Markdown{Name: "A", Slug: "a", Published: true, IsShow: true, PublishAt: local("2024-05-01 00:00"), Markdown: "body"},
+			keyLines: map[string]int{"name": 2, "slug": 3, "publish_at": 4},
+		},
+		{
+			name:     "toml",
+			content:  "+++\nname = \"A\"\n\nis_show = false\nexpire_at = 2024-05-01T08:30:00\n+++\n",
+			want:     Markdown{Name: "A", Published: true, ExpireAt: local("2024-05-01 08:30")},
+			keyLines: map[string]int{"name": 2, "is_show": 4, "expire_at": 5},
+		},
+		{
+			name:     "json",
+			content:  "{\n  \"name\": \"A\",\n  \"menu\": \"footer\"\n}\n",
+			want:     Markdown{Name: "A", Published: true, IsShow: true, Menu: Strings{"footer"}},
+			keyLines: map[string]int{"name": 2, "menu": 3},
+		},
+		{
+			name:    "shortcode body",
+			content: "{{< link \"a\" >}}",
+			want:    Markdown{Published: true, IsShow: true, Markdown: "{{< link \"a\" >}}"},
+		},
+		{
+			name:    "invalid date",
+			content: "---\nname: A\npublish_at: soon\n---\n",
+			errLine: 3,
+		},
+		{
+			name:    "yaml syntax error",
+			content: "---\nname: A\n\tslug: a\n---\n",
+			errLine: 3,
+		},
+		{
+			name:    "not a mapping",
+			content: "---\n- a\n---\n",
+			errLine: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, keyLines, err := Parse([]byte(tt.content))
+			if tt.errLine > 0 {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("err = %v, want *ParseError", err)
+				}
+				if parseErr.Line != tt.errLine {
+					t.Errorf("error line = %d, want %d (%v)", parseErr.Line, tt.errLine, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got.Name != tt.want.Name || got.Slug != tt.want.Slug || got.Published != tt.want.Published ||
+				got.IsShow != tt.want.IsShow || got.Markdown != tt.want.Markdown ||
+				!got.PublishAt.Equal(tt.want.PublishAt) || !got.ExpireAt.Equal(tt.want.ExpireAt) ||
+				len(got.Menu) != len(tt.want.Menu) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+
+			for key, line := range tt.keyLines {
+				if keyLines[key] != line {
+					t.Errorf("keyLines[%s] = %d, want %d", key, keyLines[key], line)
+				}
+			}
+		})
+	}
+}
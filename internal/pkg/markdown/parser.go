@@ -1,20 +1,9 @@
 package markdown
 
 import (
-	"bufio"
-	"bytes"
-	"html/template"
-	"io"
+	"errors"
 	"os"
-	"regexp"
-	"strings"
 	"time"
-
-	"github.com/yuin/goldmark"
-	"github.com/yuin/goldmark/extension"
-	"github.com/yuin/goldmark/parser"
-	"github.com/yuin/goldmark/renderer/html"
-	"gopkg.in/yaml.v3"
 )
 
 // Document Markdown文档结构体，用于表示单个Markdown文档的元数据和内容
@@ -23,7 +12,7 @@ type Markdown struct {
 	Keywords    string    `yaml:"keywords"`    // 关键词，用于SEO和搜索
 	Description string    `yaml:"description"` // 文档摘要，简短描述文档内容
 	Published   bool      `yaml:"published"`   // 是否发布，false表示草稿
-	IsShow      bool      `yaml:"is_show"`     // 是否显示 false表示不显示
+	IsShow      bool      `yaml:"is_show"`     // 是否显示 false表示隐藏，但仍可通过预览链接访问
 	Sort        int       `yaml:"sort"`        // 排序权重，数字越大优先级越高
 	Icon        string    `yaml:"icon"`        // 文档图标URL
 	Url         string    `yaml:"url"`         // 文档链接URL
@@ -33,12 +22,21 @@ type Markdown struct {
 	Custom      any       `yaml:"custom"`      // 自定义数据
 	Slug        string    `yaml:"slug"`        // 文档唯一标识，用于URL路径
 	Category    string    `yaml:"category"`    // 文档所属分类名
+	Items       []string  `yaml:"items"`       // 合集中按顺序列出的文档slug
+	Featured    bool      `yaml:"featured"`    // 是否在首页推荐
+	PinUntil    time.Time `yaml:"pin_until"`   // 推荐截止时间，为空表示一直推荐
+	PublishAt   time.Time `yaml:"publish_at"`  // 定时发布时间，为空表示立即发布
+	ExpireAt    time.Time `yaml:"expire_at"`   // 过期时间，为空表示永不过期
+	Query       string    `yaml:"query"`       // 合集使用的过滤表达式
+	Layout      string    `yaml:"layout"`      // 页面模板变体，如 table 对应 category-table.html
+	Template    string    `yaml:"template"`    // 指定页面模板文件，优先于 layout
+	SortBy      string    `yaml:"sort_by"`     // 分类页文档的默认排序字段，仅 _index.md 使用
+	SortOrder   string    `yaml:"sort_order"`  // 分类页文档的默认排序顺序，asc 或 desc
+	Menu        Strings   `yaml:"menu"`        // 页面出现在哪些菜单中，如 header、footer
 	UpdateTime  time.Time // 修改时间，自动从文件属性获取
 	Markdown    string    // Markdown原始内容
 }
 
-var htmlTagRegex = regexp.MustCompile("<[^>]*>")
-
 // ParseFile markdown 文件解析方法
 func Parser(filePath string) (markdownDoc Markdown, err error) {
 
@@ -47,98 +45,45 @@ func Parser(filePath string) (markdownDoc Markdown, err error) {
 		return markdownDoc, err
 	}
 
-	c, err := os.Open(filePath)
+	content, err := os.ReadFile(filePath)
 	if err != nil {
 		return markdownDoc, err
 	}
-	defer c.Close()
 
-	content, err := io.ReadAll(c)
+	markdownDoc, _, err = Parse(content)
 	if err != nil {
-		return markdownDoc, err
-	}
-
-	frontMatter, markdownContent, err := splitFrontMatter(content)
-	if err != nil {
-		return markdownDoc, err
-	}
-
-	markdownDoc.Published = true
-
-	if frontMatter != nil {
-		if err := yaml.Unmarshal(frontMatter, &markdownDoc); err != nil {
-			return markdownDoc, err
+		var parseErr *ParseError
+		if errors.As(err, &parseErr) {
+			parseErr.File = filePath
 		}
+		return markdownDoc, err
 	}
 
-	// if !markdownDoc.Published {
-	// 	return Markdown{}, errors.New("文档未发布")
-	// }
-
-	markdownDoc.Markdown = markdownContent
 	markdownDoc.UpdateTime = info.ModTime()
 
 	return markdownDoc, nil
 }
 
-// SplitFrontMatter
-func splitFrontMatter(content []byte) (frontCont []byte, mdContent string, err error) {
+// Parse 解析 markdown 内容，返回 front matter 顶层字段所在的行号
+func Parse(content []byte) (markdownDoc Markdown, keyLines map[string]int, err error) {
 
-	contents := bytes.NewBuffer(content)
-	if !strings.HasPrefix(contents.String(), "---") {
-		return nil, contents.String(), nil
+	frontMatter, markdownContent, err := SplitFrontMatter(content)
+	if err != nil {
+		return markdownDoc, nil, err
 	}
 
-	scanner := bufio.NewScanner(contents)
-
-	var frontMatter bytes.Buffer
-	var markdownContent bytes.Buffer
-
-	state := "start"
-	frontMatterLines := 0
-
-	for scanner.Scan() {
-		line := scanner.Text()
+	// 未声明时默认发布并显示
+	markdownDoc.Published = true
+	markdownDoc.IsShow = true
 
-		switch state {
-		case "start":
-			if line == "---" {
-				state = "frontmatter"
-				frontMatterLines++
-			}
-		case "frontmatter":
-			frontMatterLines++
-			if line == "---" && frontMatterLines > 1 {
-				state = "content"
-				continue
-			}
-			frontMatter.WriteString(line + "\n")
-		case "content":
-			markdownContent.WriteString(line + "\n")
+	if frontMatter != nil {
+		keyLines, err = frontMatter.Decode(&markdownDoc)
+		if err != nil {
+			return markdownDoc, nil, err
 		}
 	}
 
-	return frontMatter.Bytes(), markdownContent.String(), scanner.Err()
-}
-
-// ConvertMarkdownToHTML 把markdown转换为html
-func ConvertMarkdownToHTML(markdownContent []byte) template.HTML {
-
-	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM),
-		goldmark.WithParserOptions(
-			parser.WithAutoHeadingID(),
-		),
-		goldmark.WithRendererOptions(
-			html.WithHardWraps(),
-			html.WithXHTML(),
-		),
-	)
-
-	var buf bytes.Buffer
-	if err := md.Convert(markdownContent, &buf); err != nil {
-		return ""
-	}
+	markdownDoc.Markdown = markdownContent
 
-	return template.HTML(buf.String())
+	return markdownDoc, keyLines, nil
 }
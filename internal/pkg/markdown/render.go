@@ -0,0 +1,367 @@
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"mdnav/internal/pkg/linkpolicy"
+	"mdnav/internal/pkg/shortcode"
+	"mdnav/internal/pkg/wikilink"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// 标题 id 的生成方式
+const (
+	HeadingIDAuto    = "auto"    // goldmark 默认规则，只保留 ASCII 字母数字，中文标题为 heading、heading-1
+	HeadingIDUnicode = "unicode" // 保留中文等各语言的文字
+	HeadingIDNone    = "none"    // 不生成，只使用 {#id} 手动指定的
+)
+
+// 每分钟阅读量：中日韩文字按字计，其他语言按单词计
+const (
+	cjkPerMinute  = 400
+	wordPerMinute = 200
+)
+
+// Options Markdown 渲染选项，对应配置文件的 markdown 部分
+type Options struct {
+	Footnotes      bool   `mapstructure:"footnotes"`       // 脚注 [^1]
+	DefinitionList bool   `mapstructure:"definition_list"` // 定义列表
+	Typographer    bool   `mapstructure:"typographer"`     // 把 "" -- ... 替换为排版符号
+	CJK            bool   `mapstructure:"cjk"`             // 中文之间的换行不产生空格
+	HardWraps      bool   `mapstructure:"hard_wraps"`      // 单个换行渲染为 <br>
+	Unsafe         bool   `mapstructure:"unsafe"`          // 保留正文中的原始 HTML
+	XHTML          bool   `mapstructure:"xhtml"`           // 输出 XHTML 风格的单标签
+	HeadingIDs     string `mapstructure:"heading_ids"`     // 标题 id 的生成方式：auto、unicode、none
+	TocMinLevel    int    `mapstructure:"toc_min_level"`   // 目录包含的最高级标题
+	TocMaxLevel    int    `mapstructure:"toc_max_level"`   // 目录包含的最低级标题
+}
+
+// DefaultOptions 未配置时的渲染选项
+func DefaultOptions() Options {
+	return Options{
+		HardWraps:   true,
+		XHTML:       true,
+		HeadingIDs:  HeadingIDAuto,
+		TocMinLevel: 2,
+		TocMaxLevel: 3,
+	}
+}
+
+// TocItem 目录项
+type TocItem struct {
+	Level    int        `json:"level"`
+	Text     string     `json:"text"`
+	ID       string     `json:"id"`
+	Children []*TocItem `json:"children,omitempty"`
+}
+
+// Rendered 渲染结果
+type Rendered struct {
+	HTML        template.HTML `json:"html"`
+	TOC         []*TocItem    `json:"toc"`
+	WordCount   int           `json:"word_count"`   // 字数，中日韩文字按字计，其他按单词计
+	ReadingTime int           `json:"reading_time"` // 预计阅读分钟数，至少为 1，没有正文时为 0
+}
+
+var (
+	engineMx sync.RWMutex
+	engine   goldmark.Markdown
+	options  = DefaultOptions()
+)
+
+// Configure 按选项重新创建渲染引擎，之后的渲染都使用同一个引擎
+func Configure(opts Options) error {
+
+	switch opts.HeadingIDs {
+	case "":
+		opts.HeadingIDs = HeadingIDAuto
+	case HeadingIDAuto, HeadingIDUnicode, HeadingIDNone:
+	default:
+		return fmt.Errorf("markdown.heading_ids 只能是 auto、unicode 或 none：%q", opts.HeadingIDs)
+	}
+	if opts.TocMinLevel < 1 || opts.TocMinLevel > 6 {
+		opts.TocMinLevel = 1
+	}
+	if opts.TocMaxLevel < opts.TocMinLevel || opts.TocMaxLevel > 6 {
+		opts.TocMaxLevel = 6
+	}
+
+	md := newEngine(opts)
+
+	engineMx.Lock()
+	engine, options = md, opts
+	engineMx.Unlock()
+
+	return nil
+}
+
+func newEngine(opts Options) goldmark.Markdown {
+
+	extensions := []goldmark.Extender{extension.GFM, shortcode.Extension(ConvertMarkdownToHTML), wikilink.Extension}
+	if opts.Footnotes {
+		extensions = append(extensions, extension.Footnote)
+	}
+	if opts.DefinitionList {
+		extensions = append(extensions, extension.DefinitionList)
+	}
+	if opts.Typographer {
+		extensions = append(extensions, extension.Typographer)
+	}
+	if opts.CJK {
+		extensions = append(extensions, extension.NewCJK(extension.WithEastAsianLineBreaks(), extension.WithEscapedSpace()))
+	}
+
+	// 链接策略在渲染时读取，修改策略后不需要重新创建引擎
+	parserOptions := []parser.Option{
+		parser.WithAttribute(),
+		parser.WithASTTransformers(util.Prioritized(linkpolicy.Transformer, 500)),
+	}
+	if opts.HeadingIDs != HeadingIDNone {
+		parserOptions = append(parserOptions, parser.WithAutoHeadingID())
+	}
+
+	var rendererOptions []renderer.Option
+	if opts.HardWraps {
+		rendererOptions = append(rendererOptions, gmhtml.WithHardWraps())
+	}
+	if opts.XHTML {
+		rendererOptions = append(rendererOptions, gmhtml.WithXHTML())
+	}
+	if opts.Unsafe {
+		rendererOptions = append(rendererOptions, gmhtml.WithUnsafe())
+	}
+
+	return goldmark.New(
+		goldmark.WithExtensions(extensions...),
+		goldmark.WithParserOptions(parserOptions...),
+		goldmark.WithRendererOptions(rendererOptions...),
+	)
+}
+
+// current 当前的渲染引擎，未调用 Configure 时使用默认选项
+func current() (goldmark.Markdown, Options) {
+
+	engineMx.RLock()
+	md, opts := engine, options
+	engineMx.RUnlock()
+
+	if md != nil {
+		return md, opts
+	}
+
+	if err := Configure(opts); err != nil {
+		return newEngine(DefaultOptions()), DefaultOptions()
+	}
+	return current()
+}
+
+// Render 渲染 Markdown，同时生成目录和阅读时间
+func Render(content string) Rendered {
+	return RenderWith(content, nil)
+}
+
+// RenderWith 渲染 Markdown，图片地址先经过 resolve 处理，用于把相对路径转换为文档所在目录中的资源
+func RenderWith(content string, resolve func(ref string) string) Rendered {
+
+	md, opts := current()
+	source := []byte(content)
+
+	pc := parser.NewContext()
+	if opts.HeadingIDs == HeadingIDUnicode {
+		pc = parser.NewContext(parser.WithIDs(newUnicodeIDs()))
+	}
+
+	doc := md.Parser().Parse(text.NewReader(source), parser.WithContext(pc))
+	if resolve != nil {
+		resolveImages(doc, resolve)
+	}
+
+	var buf bytes.Buffer
+	if err := md.Renderer().Render(&buf, source, doc); err != nil {
+		return Rendered{}
+	}
+
+	rendered := Rendered{
+		HTML: template.HTML(buf.String()),
+		TOC:  tableOfContents(doc, source, opts),
+	}
+	rendered.WordCount, rendered.ReadingTime = readingTime(buf.String())
+
+	return rendered
+}
+
+// ConvertMarkdownToHTML 把markdown转换为html
+func ConvertMarkdownToHTML(markdownContent []byte) template.HTML {
+	return Render(string(markdownContent)).HTML
+}
+
+// resolveImages 替换正文中的图片地址
+func resolveImages(doc ast.Node, resolve func(ref string) string) {
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if img, ok := n.(*ast.Image); ok && entering {
+			img.Destination = []byte(resolve(string(img.Destination)))
+		}
+		return ast.WalkContinue, nil
+	})
+}
+
+// tableOfContents 按层级生成目录，跳过的层级（如 h2 下直接是 h4）挂在最近的上级下面
+func tableOfContents(doc ast.Node, source []byte, opts Options) []*TocItem {
+
+	var root []*TocItem
+	var stack []*TocItem
+
+	ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		heading, ok := n.(*ast.Heading)
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		if heading.Level < opts.TocMinLevel || heading.Level > opts.TocMaxLevel {
+			return ast.WalkSkipChildren, nil
+		}
+
+		item := &TocItem{Level: heading.Level, Text: plainText(heading, source)}
+		if id, ok := heading.AttributeString("id"); ok {
+			if b, ok := id.([]byte); ok {
+				item.ID = string(b)
+			}
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].Level >= item.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			root = append(root, item)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, item)
+		}
+		stack = append(stack, item)
+
+		return ast.WalkSkipChildren, nil
+	})
+
+	return root
+}
+
+// plainText 节点中的纯文本
+func plainText(n ast.Node, source []byte) string {
+
+	var b strings.Builder
+	ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch v := c.(type) {
+		case *ast.Text:
+			b.Write(v.Segment.Value(source))
+			if v.SoftLineBreak() {
+				b.WriteByte(' ')
+			}
+		case *ast.String:
+			b.Write(v.Value)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	return strings.TrimSpace(b.String())
+}
+
+var htmlTagRegex = regexp.MustCompile("<[^>]*>")
+
+// readingTime 统计渲染后正文的字数和预计阅读分钟数
+func readingTime(rendered string) (words, minutes int) {
+
+	plain := html.UnescapeString(htmlTagRegex.ReplaceAllString(rendered, " "))
+
+	cjk, latin := 0, 0
+	inWord := false
+	for _, r := range plain {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				latin++
+			}
+			inWord = true
+		default:
+			inWord = false
+		}
+	}
+
+	words = cjk + latin
+	if words == 0 {
+		return 0, 0
+	}
+
+	minutes = int(math.Ceil(float64(cjk)/cjkPerMinute + float64(latin)/wordPerMinute))
+	return words, max(minutes, 1)
+}
+
+// unicodeIDs 保留各语言文字的标题 id，如 "安装 Go" 生成 "安装-go"，重复时追加 -1、-2
+type unicodeIDs struct {
+	values map[string]bool
+}
+
+func newUnicodeIDs() parser.IDs {
+	return &unicodeIDs{values: make(map[string]bool)}
+}
+
+func (s *unicodeIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(string(util.TrimRightSpace(util.TrimLeftSpace(value)))) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			dash = false
+		case unicode.IsSpace(r) || r == '-':
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+
+	result := strings.TrimSuffix(b.String(), "-")
+	if result == "" {
+		result = "heading"
+		if kind != ast.KindHeading {
+			result = "id"
+		}
+	}
+
+	id := result
+	for i := 1; s.values[id]; i++ {
+		id = fmt.Sprintf("%s-%d", result, i)
+	}
+	s.values[id] = true
+
+	return []byte(id)
+}
+
+func (s *unicodeIDs) Put(value []byte) {
+	s.values[string(value)] = true
+}
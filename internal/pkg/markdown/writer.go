@@ -0,0 +1,149 @@
+package markdown
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterOut 写入文件时的 front matter，零值字段省略
+type frontMatterOut struct {
+	Name        string    `yaml:"name"`
+	Keywords    string    `yaml:"keywords,omitempty"`
+	Description string    `yaml:"description,omitempty"`
+	Published   bool      `yaml:"published"`
+	Sort        int       `yaml:"sort,omitempty"`
+	Icon        string    `yaml:"icon,omitempty"`
+	Url         string    `yaml:"url,omitempty"`
+	Tags        []string  `yaml:"tags,omitempty,flow"`
+	Image       string    `yaml:"image,omitempty"`
+	Featured    bool      `yaml:"featured,omitempty"`
+	CreateTime  time.Time `yaml:"create_time,omitempty"`
+}
+
+// Marshal 把文档序列化为带 YAML front matter 的 markdown 文件内容
+func Marshal(md Markdown) ([]byte, error) {
+
+	front, err := yaml.Marshal(frontMatterOut{
+		Name:        md.Name,
+		Keywords:    md.Keywords,
+		Description: md.Description,
+		Published:   md.Published,
+		Sort:        md.Sort,
+		Icon:        md.Icon,
+		Url:         md.Url,
+		Tags:        md.Tags,
+		Image:       md.Image,
+		Featured:    md.Featured,
+		CreateTime:  md.CreateTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(front)
+	buf.WriteString("---\n")
+
+	if body := strings.TrimSpace(md.Markdown); body != "" {
+		buf.WriteString("\n")
+		buf.WriteString(body)
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// UpdateFrontMatter 修改 front matter 中的字段，保留其他字段、注释和正文，
+// values 中值为 nil 的字段会被删除。TOML/JSON 格式的 front matter 会转换为 YAML
+func UpdateFrontMatter(content []byte, values map[string]any) ([]byte, error) {
+
+	front, body, err := SplitFrontMatter(content)
+	if err != nil {
+		return nil, err
+	}
+
+	var root *yaml.Node
+	if front != nil {
+		node, err := front.node()
+		if err != nil {
+			return nil, err
+		}
+		if len(node.Content) > 0 {
+			root = node.Content[0]
+		}
+	}
+	if root == nil {
+		root = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, &ParseError{Line: 1, Column: 1, Err: errors.New("front matter 必须是键值对")}
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := setMappingValue(root, key, values[key]); err != nil {
+			return nil, err
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return nil, err
+	}
+	encoder.Close()
+	buf.WriteString("---\n")
+	buf.WriteString(body)
+
+	return buf.Bytes(), nil
+}
+
+// setMappingValue 设置 mapping 节点中的字段，不存在时追加到末尾
+func setMappingValue(mapping *yaml.Node, key string, value any) error {
+
+	index := -1
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			index = i
+			break
+		}
+	}
+
+	if value == nil {
+		if index >= 0 {
+			mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+		}
+		return nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return err
+	}
+	if node.Kind == yaml.SequenceNode {
+		node.Style = yaml.FlowStyle
+	}
+
+	if index >= 0 {
+		node.HeadComment = mapping.Content[index+1].HeadComment
+		node.LineComment = mapping.Content[index+1].LineComment
+		*mapping.Content[index+1] = node
+		return nil
+	}
+
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &node)
+	return nil
+}
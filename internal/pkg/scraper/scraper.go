@@ -0,0 +1,277 @@
+package scraper
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const maxPageBytes = 2 << 20 // 只读取网页的前 2MB
+
+// Meta 网页元数据
+type Meta struct {
+	Url         string   `json:"url"`       // 请求的地址
+	FinalUrl    string   `json:"final_url"` // 跳转后的地址
+	Canonical   string   `json:"canonical"` // <link rel="canonical"> 或 og:url
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Image       string   `json:"image"` // og:image 或 twitter:image
+	Keywords    []string `json:"keywords"`
+	SiteName    string   `json:"site_name"`
+	Charset     string   `json:"charset"` // 识别出的网页编码
+}
+
+// Options 抓取选项，零值字段使用默认值
+type Options struct {
+	Client    *http.Client
+	Timeout   time.Duration // 请求超时，默认 10 秒
+	UserAgent string
+}
+
+// Scraper 网页元数据抓取器
+type Scraper struct {
+	opts   Options
+	client *http.Client
+}
+
+// New 创建抓取器
+func New(opts Options) *Scraper {
+
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "Mozilla/5.0 (compatible; mdnav-scraper)"
+	}
+
+	client := &http.Client{Timeout: opts.Timeout}
+	if opts.Client != nil {
+		copied := *opts.Client
+		if copied.Timeout == 0 {
+			copied.Timeout = opts.Timeout
+		}
+		client = &copied
+	}
+
+	return &Scraper{opts: opts, client: client}
+}
+
+// Fetch 请求网页并提取元数据
+func (s *Scraper) Fetch(ctx context.Context, pageUrl string) (*Meta, error) {
+
+	u, err := url.Parse(strings.TrimSpace(pageUrl))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("无效的网址 %q", pageUrl)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", s.opts.UserAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9,en;q=0.8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s 返回 %d", u, resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return nil, fmt.Errorf("%s 不是网页：%s", u, contentType)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := Parse(content, contentType, resp.Request.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	meta.Url = u.String()
+
+	return meta, nil
+}
+
+// Parse 从网页内容中提取元数据，contentType 为响应头，用于识别编码，pageUrl 用于解析相对地址
+func Parse(content []byte, contentType, pageUrl string) (*Meta, error) {
+
+	base, err := url.Parse(pageUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	enc, name := detectCharset(content, contentType)
+	if enc != nil {
+		if decoded, err := enc.NewDecoder().Bytes(content); err == nil {
+			content = decoded
+		}
+	}
+
+	meta := &Meta{Url: pageUrl, FinalUrl: pageUrl, Charset: name}
+
+	var (
+		title     strings.Builder
+		inTitle   bool
+		props     = make(map[string]string) // meta name/property -> content，先出现的优先
+		canonical string
+		imageSrc  string
+	)
+
+	z := html.NewTokenizer(bytes.NewReader(content))
+	for done := false; !done; {
+		switch z.Next() {
+		case html.ErrorToken:
+			done = true
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			attrs := make(map[string]string, len(tok.Attr))
+			for _, a := range tok.Attr {
+				attrs[strings.ToLower(a.Key)] = strings.TrimSpace(a.Val)
+			}
+
+			switch tok.Data {
+			case "title":
+				inTitle = title.Len() == 0
+			case "meta":
+				key := strings.ToLower(attrs["property"])
+				if key == "" {
+					key = strings.ToLower(attrs["name"])
+				}
+				if key != "" && attrs["content"] != "" {
+					if _, ok := props[key]; !ok {
+						props[key] = attrs["content"]
+					}
+				}
+			case "link":
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					switch rel {
+					case "canonical":
+						if canonical == "" {
+							canonical = attrs["href"]
+						}
+					case "image_src":
+						if imageSrc == "" {
+							imageSrc = attrs["href"]
+						}
+					}
+				}
+			case "body":
+				done = true // 元数据都在 head 中
+			}
+
+		case html.EndTagToken:
+			if name, _ := z.TagName(); string(name) == "title" {
+				inTitle = false
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(z.Text())
+			}
+		}
+	}
+
+	meta.Title = firstOf(props["og:title"], oneLine(title.String()), props["twitter:title"])
+	meta.Description = oneLine(firstOf(props["description"], props["og:description"], props["twitter:description"]))
+	meta.SiteName = props["og:site_name"]
+	meta.Image = resolve(base, firstOf(props["og:image:secure_url"], props["og:image"], props["twitter:image"], props["twitter:image:src"], imageSrc))
+	meta.Canonical = resolve(base, firstOf(canonical, props["og:url"]))
+	meta.Keywords = splitKeywords(props["keywords"])
+
+	if meta.Title == "" && meta.Description == "" && meta.Canonical == "" {
+		return meta, errors.New("网页中没有找到标题和描述")
+	}
+
+	return meta, nil
+}
+
+var metaCharsetRegex = regexp.MustCompile(`(?i)<meta[^>]+charset\s*=\s*["']?\s*([\w-]+)`)
+
+// detectCharset 识别网页编码：BOM、响应头和前 1024 字节中的声明优先，
+// 其次是网页中任意位置的 meta charset，都没有时内容不是合法 UTF-8 则按 GB18030（兼容 GBK、GB2312）处理
+func detectCharset(content []byte, contentType string) (encoding.Encoding, string) {
+
+	if enc, name, certain := charset.DetermineEncoding(content, contentType); certain {
+		return enc, name
+	}
+
+	if m := metaCharsetRegex.FindSubmatch(content); m != nil {
+		if enc, name := charset.Lookup(string(m[1])); enc != nil {
+			return enc, name
+		}
+	}
+
+	if utf8.Valid(content) {
+		return unicode.UTF8, "utf-8"
+	}
+
+	return simplifiedchinese.GB18030, "gb18030"
+}
+
+// resolve 把相对地址转换为绝对地址，只保留 http(s) 地址
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+	u, err := base.Parse(ref)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return u.String()
+}
+
+// splitKeywords 按中英文逗号、分号、顿号、竖线拆分关键词并去重
+func splitKeywords(s string) []string {
+
+	var list []string
+	seen := make(map[string]bool)
+
+	for _, v := range strings.FieldsFunc(s, func(r rune) bool {
+		return strings.ContainsRune(",，;；、|", r)
+	}) {
+		v = strings.TrimSpace(v)
+		if v != "" && !seen[strings.ToLower(v)] {
+			seen[strings.ToLower(v)] = true
+			list = append(list, v)
+		}
+	}
+
+	return list
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
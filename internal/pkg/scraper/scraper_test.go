@@ -0,0 +1,197 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func gbk(s string) string {
+	b, err := simplifiedchinese.GBK.NewEncoder().String(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+func TestParse(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+		want        Meta
+		err         string // 期望的错误信息片段
+	}{
+		{
+			name: "open graph first",
+			content: `<html><head>
+<title> Page
+  Title </title>
+<meta name="description" content=" 描述
+ 两行 ">
+<meta property="og:title" content="OG Title">
+<meta property="og:title" content="Second">
+<meta property="OG:Image" content="/img/a.png">
+<meta property="og:site_name" content="Site">
+<meta name="keywords" content="Go, go，工具;工具、效率 | ">
+<link rel="canonical" href="../canonical">
+</head><body><title>ignored</title></body></html>`,
+			want: Meta{
+				Title:       "OG Title",
+				Description: "描述 两行",
+				Image:       "https://example.com/img/a.png",
+				Canonical:   "https://example.com/canonical",
+				Keywords:    []string{"Go", "工具", "效率"},
+				SiteName:    "Site",
+				Charset:     "utf-8",
+			},
+		},
+		{
+			name:    "title fallback",
+			content: `<title>A &amp; B</title><meta name="twitter:image:src" content="//cdn.example.com/b.png"><meta property="og:url" content="https://example.com/x">`,
+			want: Meta{
+				Title:     "A & B",
+				Image:     "https://cdn.example.com/b.png",
+				Canonical: "https://example.com/x",
+				Charset:   "utf-8",
+			},
+		},
+		{
+			name:    "unsafe urls dropped",
+			content: `<title>T</title><meta property="og:image" content="javascript:alert(1)"><link rel="canonical" href="data:text/html,x"><link rel="image_src" href="/c.png">`,
+			want:    Meta{Title: "T", Charset: "utf-8"},
+		},
+		{
+			name:    "image_src",
+			content: `<title>T</title><link rel="shortcut image_src" href="/c.png">`,
+			want:    Meta{Title: "T", Image: "https://example.com/c.png", Charset: "utf-8"},
+		},
+		{
+			name:        "gbk from header",
+			content:     "<title>" + gbk("中文标题") + "</title>",
+			contentType: "text/html; charset=GBK",
+			want:        Meta{Title: "中文标题", Charset: "gbk"},
+		},
+		{
+			name:    "gbk from late meta",
+			content: strings.Repeat(" ", 2000) + `<meta charset="gb2312"><title>` + gbk("标题") + "</title>",
+			want:    Meta{Title: "标题", Charset: "gbk"},
+		},
+		{
+			name:    "gbk without declaration",
+			content: "<title>" + gbk("没有声明编码") + "</title>",
+			want:    Meta{Title: "没有声明编码", Charset: "gb18030"},
+		},
+		{
+			name:    "unclosed title",
+			content: "<title>Broken <meta name=description content=x>",
+			want:    Meta{Title: "Broken <meta name=description content=x>", Charset: "utf-8"},
+		},
+		{
+			name:    "metadata in body ignored",
+			content: `<html><body><meta name="description" content="x"><title>T</title></body></html>`,
+			err:     "网页中没有找到标题和描述",
+		},
+		{
+			name:    "empty",
+			content: "",
+			err:     "网页中没有找到标题和描述",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := Parse([]byte(tt.content), tt.contentType, "https://example.com/a/page")
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tt.want.Url = "https://example.com/a/page"
+			tt.want.FinalUrl = tt.want.Url
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetch(t *testing.T) {
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<title>` + r.UserAgent() + `</title><link rel="canonical" href="/canonical">`))
+	})
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/page", http.StatusFound)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"title":"x"}`))
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<!--" + strings.Repeat("x", maxPageBytes) + "--><title>too late</title>"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	s := New(Options{UserAgent: "test-agent"})
+
+	tests := []struct {
+		name string
+		url  string
+		want Meta
+		err  string
+	}{
+		{
+			name: "page",
+			url:  server.URL + "/page",
+			want: Meta{Url: server.URL + "/page", FinalUrl: server.URL + "/page", Canonical: server.URL + "/canonical", Title: "test-agent", Charset: "utf-8"},
+		},
+		{
+			name: "redirect",
+			url:  " " + server.URL + "/redirect ",
+			want: Meta{Url: server.URL + "/redirect", FinalUrl: server.URL + "/page", Canonical: server.URL + "/canonical", Title: "test-agent", Charset: "utf-8"},
+		},
+		{name: "not found", url: server.URL + "/missing", err: "返回 404"},
+		{name: "not html", url: server.URL + "/json", err: "不是网页"},
+		{name: "only first bytes read", url: server.URL + "/large", err: "网页中没有找到标题和描述"},
+		{name: "unsupported scheme", url: "file:///etc/passwd", err: "无效的网址"},
+		{name: "no host", url: "http:///x", err: "无效的网址"},
+		{name: "empty", url: "", err: "无效的网址"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			got, err := s.Fetch(context.Background(), tt.url)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("error = %v, want containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("got  %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
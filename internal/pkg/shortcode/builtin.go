@@ -0,0 +1,124 @@
+package shortcode
+
+import (
+	"errors"
+	"html"
+	"html/template"
+	"net/url"
+	"strings"
+
+	"mdnav/internal/pkg/linkpolicy"
+)
+
+func init() {
+	Register("figure", figure)
+	Register("notice", notice)
+	Register("details", details)
+}
+
+// notice 支持的类型
+var noticeTypes = map[string]string{
+	"info":    "提示",
+	"tip":     "技巧",
+	"warning": "注意",
+	"danger":  "警告",
+}
+
+// figure 带说明的图片：{{< figure src="/a.png" alt="说明" caption="图注" link="https://..." >}}
+func figure(c *Call) (template.HTML, error) {
+
+	src := SafeURL(c.Value("src", 0))
+	if src == "" {
+		return "", errors.New("缺少 src 或 src 不是可用的链接")
+	}
+
+	alt := c.Get("alt")
+	caption := c.Get("caption")
+	if alt == "" {
+		alt = caption
+	}
+
+	var b strings.Builder
+	b.WriteString(`<figure class="shortcode-figure">`)
+
+	lazy := ""
+	if linkpolicy.Current().LazyImages {
+		lazy = ` loading="lazy"`
+	}
+	img := `<img src="` + html.EscapeString(src) + `" alt="` + html.EscapeString(alt) + `"` + lazy + `>`
+	if href, attrs := OutboundLink(c.Get("link")); href != "" {
+		img = `<a href="` + html.EscapeString(href) + `"` + attrs + `>` + img + `</a>`
+	}
+	b.WriteString(img)
+
+	if caption != "" {
+		b.WriteString(`<figcaption>` + html.EscapeString(caption) + `</figcaption>`)
+	}
+	b.WriteString(`</figure>`)
+
+	return template.HTML(b.String()), nil
+}
+
+// notice 提示框：{{< notice warning "标题" >}}Markdown 内容{{< /notice >}}，类型为 info、tip、warning、danger
+func notice(c *Call) (template.HTML, error) {
+
+	kind := c.Value("type", 0)
+	if kind == "" {
+		kind = "info"
+	}
+	label, ok := noticeTypes[kind]
+	if !ok {
+		return "", errors.New("不支持的类型 " + kind + "，可选 info、tip、warning、danger")
+	}
+
+	title := c.Value("title", 1)
+	if title == "" {
+		title = label
+	}
+
+	return template.HTML(`<div class="shortcode-notice notice-` + kind + `"><p class="notice-title">` +
+		html.EscapeString(title) + `</p>` + string(c.InnerHTML()) + `</div>`), nil
+}
+
+// details 折叠内容：{{< details "标题" open >}}Markdown 内容{{< /details >}}
+func details(c *Call) (template.HTML, error) {
+
+	summary := c.Value("summary", 0)
+	if summary == "" {
+		summary = "详情"
+	}
+
+	open := ""
+	if c.Has("open") {
+		open = " open"
+	}
+
+	return template.HTML(`<details class="shortcode-details"` + open + `><summary>` + html.EscapeString(summary) +
+		`</summary>` + string(c.InnerHTML()) + `</details>`), nil
+}
+
+// SafeURL 只允许 http、https、mailto 和站内链接，其他协议（如 javascript:）返回空字符串
+func SafeURL(raw string) string {
+
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return ""
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return raw
+	}
+
+	return ""
+}
+
+// OutboundLink 按链接策略处理短代码中的链接，返回地址和 target、rel 属性，不可用或被拦截时地址为空
+func OutboundLink(raw string) (href, attrs string) {
+
+	p := linkpolicy.Current()
+	l := p.Resolve(SafeURL(raw))
+
+	return l.Url, p.HTMLAttrs(l)
+}
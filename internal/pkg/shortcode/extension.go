@@ -0,0 +1,234 @@
+package shortcode
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+var (
+	// 开始标签：{{< name args >}}，以 /> 结尾时为单行短代码
+	openRegex = regexp.MustCompile(`^\{\{<\s*([A-Za-z][\w-]*)((?:"(?:[^"\\]|\\.)*"|'[^']*'|[^"'>]|>[^}])*?)\s*(/?)>\}\}`)
+	// 结束标签：{{< /name >}}
+	closeRegex = regexp.MustCompile(`^\s*\{\{<\s*/\s*([A-Za-z][\w-]*)\s*>\}\}\s*$`)
+)
+
+// KindBlock 独占一行或成对出现的短代码
+var KindBlock = ast.NewNodeKind("ShortcodeBlock")
+
+// KindInline 段落中的短代码
+var KindInline = ast.NewNodeKind("ShortcodeInline")
+
+// Block 块级短代码节点，成对短代码的内容保存在 Lines 中
+type Block struct {
+	ast.BaseBlock
+	Name   string
+	Raw    string // 名称之后的参数原文
+	paired bool
+	depth  int // 嵌套的同名短代码层数
+}
+
+func (n *Block) Kind() ast.NodeKind { return KindBlock }
+
+func (n *Block) IsRaw() bool { return true }
+
+func (n *Block) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Name}, nil)
+}
+
+// Inline 行内短代码节点
+type Inline struct {
+	ast.BaseInline
+	Name string
+	Raw  string
+}
+
+func (n *Inline) Kind() ast.NodeKind { return KindInline }
+
+func (n *Inline) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Name": n.Name}, nil)
+}
+
+type blockParser struct{}
+
+func (p *blockParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+// Open 只处理独占一行的短代码；后文中有对应的结束标签时为成对短代码，一直读取到结束标签
+func (p *blockParser) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 {
+		return nil, parser.NoChildren
+	}
+
+	m := openRegex.FindSubmatchIndex(line[pos:])
+	if m == nil || !util.IsBlank(line[pos+m[1]:]) {
+		return nil, parser.NoChildren
+	}
+
+	node := &Block{
+		Name: string(line[pos+m[2] : pos+m[3]]),
+		Raw:  string(line[pos+m[4] : pos+m[5]]),
+	}
+	if m[6] == m[7] {
+		node.paired = hasClose(reader.Source()[segment.Stop:], node.Name)
+	}
+
+	advanceLine(reader, line, segment)
+	return node, parser.NoChildren
+}
+
+func (p *blockParser) Continue(node ast.Node, reader text.Reader, pc parser.Context) parser.State {
+
+	n := node.(*Block)
+	if !n.paired {
+		return parser.Close
+	}
+
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+
+	if m := closeRegex.FindSubmatch(line); m != nil && string(m[1]) == n.Name {
+		if n.depth == 0 {
+			advanceLine(reader, line, segment)
+			return parser.Close
+		}
+		n.depth--
+	} else if m := openRegex.FindSubmatch(bytes.TrimSpace(line)); m != nil && string(m[1]) == n.Name && len(m[3]) == 0 {
+		n.depth++
+	}
+
+	n.Lines().Append(segment)
+	advanceLine(reader, line, segment)
+	return parser.Continue | parser.NoChildren
+}
+
+// advanceLine 跳过当前行，保留换行符由解析器处理
+func advanceLine(reader text.Reader, line []byte, segment text.Segment) {
+	newline := 0
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		newline = 1
+	}
+	reader.Advance(segment.Len() - newline + segment.Padding)
+}
+
+func (p *blockParser) Close(node ast.Node, reader text.Reader, pc parser.Context) {}
+
+func (p *blockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *blockParser) CanAcceptIndentedLine() bool {
+	return false
+}
+
+// hasClose 后文中是否有独占一行的 {{< /name >}}
+func hasClose(source []byte, name string) bool {
+	for _, line := range bytes.Split(source, []byte("\n")) {
+		if m := closeRegex.FindSubmatch(line); m != nil && string(m[1]) == name {
+			return true
+		}
+	}
+	return false
+}
+
+type inlineParser struct{}
+
+func (p *inlineParser) Trigger() []byte {
+	return []byte{'{'}
+}
+
+func (p *inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+
+	line, _ := block.PeekLine()
+
+	m := openRegex.FindSubmatchIndex(line)
+	if m == nil {
+		return nil
+	}
+
+	block.Advance(m[1])
+	return &Inline{
+		Name: string(line[m[2]:m[3]]),
+		Raw:  string(line[m[4]:m[5]]),
+	}
+}
+
+type nodeRenderer struct {
+	render func([]byte) template.HTML
+}
+
+func (r *nodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindBlock, r.renderBlock)
+	reg.Register(KindInline, r.renderInline)
+}
+
+func (r *nodeRenderer) renderBlock(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Block)
+	c := r.call(n.Name, n.Raw)
+
+	var inner bytes.Buffer
+	for i := 0; i < n.Lines().Len(); i++ {
+		line := n.Lines().At(i)
+		inner.Write(line.Value(source))
+	}
+	c.Inner = inner.String()
+
+	w.WriteString(string(execute(c)))
+	w.WriteByte('\n')
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *nodeRenderer) renderInline(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Inline)
+	w.WriteString(string(execute(r.call(n.Name, n.Raw))))
+
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *nodeRenderer) call(name, raw string) *Call {
+	args, params := parseArgs(raw)
+	return &Call{Name: name, Args: args, Params: params, render: r.render}
+}
+
+type extension struct {
+	render func([]byte) template.HTML
+}
+
+// Extension 短代码的 goldmark 扩展，render 用于渲染成对短代码之间的 Markdown
+func Extension(render func([]byte) template.HTML) goldmark.Extender {
+	return &extension{render: render}
+}
+
+func (e *extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(
+		parser.WithBlockParsers(util.Prioritized(&blockParser{}, 150)),
+		parser.WithInlineParsers(util.Prioritized(&inlineParser{}, 150)),
+	)
+	m.Renderer().AddOptions(
+		renderer.WithNodeRenderers(util.Prioritized(&nodeRenderer{render: e.render}, 150)),
+	)
+}
@@ -0,0 +1,173 @@
+// Package shortcode Markdown 正文中的短代码，如 {{< figure src="/a.png" >}}、
+// {{< notice warning >}}正文{{< /notice >}}，通过 goldmark 扩展解析并按名称调用注册的函数
+package shortcode
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"regexp"
+	"strconv"
+	"sync"
+)
+
+// Call 一次短代码调用
+type Call struct {
+	Name   string            // 短代码名称
+	Args   map[string]string // 命名参数，如 src="/a.png"
+	Params []string          // 位置参数，如 notice warning 中的 warning
+	Inner  string            // 成对短代码之间的 Markdown 原文，单行短代码为空
+
+	render func([]byte) template.HTML
+}
+
+// Get 获取命名参数
+func (c *Call) Get(key string) string {
+	return c.Args[key]
+}
+
+// Arg 获取第 i 个位置参数，不存在时返回空字符串
+func (c *Call) Arg(i int) string {
+	if i < 0 || i >= len(c.Params) {
+		return ""
+	}
+	return c.Params[i]
+}
+
+// Value 优先取命名参数，没有时取第 i 个位置参数
+func (c *Call) Value(key string, i int) string {
+	if v, ok := c.Args[key]; ok {
+		return v
+	}
+	return c.Arg(i)
+}
+
+// Has 是否设置了开关参数，如 details 的 open，写成位置参数或命名参数都可以
+func (c *Call) Has(flag string) bool {
+	if v, ok := c.Args[flag]; ok {
+		return v != "false" && v != "0"
+	}
+	for _, v := range c.Params {
+		if v == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// InnerHTML 把成对短代码之间的内容按 Markdown 渲染，其中也可以继续使用短代码
+func (c *Call) InnerHTML() template.HTML {
+	if c.Inner == "" || c.render == nil {
+		return ""
+	}
+	return c.render([]byte(c.Inner))
+}
+
+// Func 短代码的实现，返回的 HTML 原样输出
+type Func func(c *Call) (template.HTML, error)
+
+// Source 内置短代码之外的来源，如模板目录中的 shortcodes/<name>.html，同名时优先于内置短代码
+type Source func(name string) (Func, bool)
+
+var (
+	mx       sync.RWMutex
+	builtins = make(map[string]Func)
+	source   Source
+)
+
+// Register 注册内置短代码，同名时覆盖
+func Register(name string, fn Func) {
+	mx.Lock()
+	defer mx.Unlock()
+	builtins[name] = fn
+}
+
+// SetSource 设置额外的短代码来源
+func SetSource(src Source) {
+	mx.Lock()
+	defer mx.Unlock()
+	source = src
+}
+
+// Lookup 按名称查找短代码
+func Lookup(name string) (Func, bool) {
+
+	mx.RLock()
+	src := source
+	fn, ok := builtins[name]
+	mx.RUnlock()
+
+	if src != nil {
+		if custom, found := src(name); found {
+			return custom, true
+		}
+	}
+
+	return fn, ok
+}
+
+// Names 所有内置短代码的名称
+func Names() []string {
+	mx.RLock()
+	defer mx.RUnlock()
+
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	return names
+}
+
+// 参数：key=value、key="带空格的值"、key='值'、"位置参数"、位置参数
+var argRegex = regexp.MustCompile(`(?:([A-Za-z_][\w-]*)=)?(?:"((?:[^"\\]|\\.)*)"|'([^']*)'|(\S+))`)
+
+// parseArgs 解析短代码名称之后的参数
+func parseArgs(raw string) (args map[string]string, params []string) {
+
+	args = make(map[string]string)
+
+	for _, m := range argRegex.FindAllStringSubmatchIndex(raw, -1) {
+
+		var value string
+		switch {
+		case m[4] >= 0: // 双引号，支持 \" 等转义
+			value = raw[m[4]:m[5]]
+			if v, err := strconv.Unquote(`"` + value + `"`); err == nil {
+				value = v
+			}
+		case m[6] >= 0: // 单引号，原样使用
+			value = raw[m[6]:m[7]]
+		default:
+			value = raw[m[8]:m[9]]
+		}
+
+		if m[2] >= 0 {
+			args[raw[m[2]:m[3]]] = value
+		} else {
+			params = append(params, value)
+		}
+	}
+
+	return args, params
+}
+
+// execute 调用短代码，未注册或出错时输出带说明的原文，方便作者发现问题
+func execute(c *Call) template.HTML {
+
+	fn, ok := Lookup(c.Name)
+	if !ok {
+		return failure(c, fmt.Errorf("未知的短代码 %s", c.Name))
+	}
+
+	out, err := fn(c)
+	if err != nil {
+		return failure(c, err)
+	}
+
+	return out
+}
+
+func failure(c *Call, err error) template.HTML {
+	return template.HTML(`<code class="shortcode-error" title="` + html.EscapeString(err.Error()) + `">` +
+		html.EscapeString("{{< "+c.Name+" >}} "+err.Error()) + `</code>`)
+}
@@ -0,0 +1,139 @@
+package shortcode
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+)
+
+func TestParseArgs(t *testing.T) {
+
+	tests := []struct {
+		raw    string
+		args   map[string]string
+		params []string
+	}{
+		{raw: "", args: map[string]string{}},
+		{raw: ` warning "标题" `, args: map[string]string{}, params: []string{"warning", "标题"}},
+		{raw: `src="/a b.png" alt='它"说"' open`, args: map[string]string{"src": "/a b.png", "alt": `它"说"`}, params: []string{"open"}},
+		{raw: `caption="a \"b\" 中"`, args: map[string]string{"caption": `a "b" 中`}},
+		{raw: `q="a >}} b"`, args: map[string]string{"q": "a >}} b"}},
+		{raw: `data-x=1 =bare`, args: map[string]string{"data-x": "1"}, params: []string{"=bare"}},
+		{raw: `title="unterminated`, args: map[string]string{"title": `"unterminated`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			args, params := parseArgs(tt.raw)
+			if !reflect.DeepEqual(args, tt.args) || !reflect.DeepEqual(params, tt.params) {
+				t.Errorf("parseArgs(%q) = %q %q, want %q %q", tt.raw, args, params, tt.args, tt.params)
+			}
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+
+	Register("echo", func(c *Call) (template.HTML, error) {
+		inner := strings.TrimSpace(string(c.InnerHTML()))
+		return template.HTML(fmt.Sprintf("[%s %v %q |%s|]", c.Name, c.Args, c.Params, inner)), nil
+	})
+
+	var md goldmark.Markdown
+	md = goldmark.New(goldmark.WithExtensions(Extension(func(source []byte) template.HTML {
+		var buf bytes.Buffer
+		md.Convert(source, &buf)
+		return template.HTML(buf.String())
+	})))
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "block",
+			input: `{{< echo a b="c d" >}}`,
+			want:  "[echo map[b:c d] [\"a\"] ||]\n",
+		},
+		{
+			name:  "inline",
+			input: "text {{< echo x >}} more",
+			want:  "<p>text [echo map[] [\"x\"] ||] more</p>\n",
+		},
+		{
+			name:  "paired",
+			input: "{{< echo >}}\n**in**\n{{< /echo >}}",
+			want:  "[echo map[] [] |<p><strong>in</strong></p>|]\n",
+		},
+		{
+			name:  "nested same name",
+			input: "{{< echo >}}\nouter\n{{< echo >}}\ninner\n{{< /echo >}}\n{{< /echo >}}",
+			want:  "[echo map[] [] |<p>outer</p>\n[echo map[] [] |<p>inner</p>|]|]\n",
+		},
+		{
+			name:  "self closing",
+			input: "{{< echo />}}\nafter\n{{< /echo >}}",
+			want:  "[echo map[] [] ||]\n<p>after\n{{&lt; /echo &gt;}}</p>\n",
+		},
+		{
+			name:  "quoted closing delimiter",
+			input: `{{< echo q="a >}} b" >}}`,
+			want:  "[echo map[q:a >}} b] [] ||]\n",
+		},
+		{
+			name:  "unknown shortcode",
+			input: "{{< nope >}}",
+			want:  "<code class=\"shortcode-error\" title=\"未知的短代码 nope\">{{&lt; nope &gt;}} 未知的短代码 nope</code>\n",
+		},
+
+		// 未闭合的短代码按普通文本处理，不吞掉后面的内容
+		{
+			name:  "missing close tag",
+			input: "{{< echo >}}\nno close",
+			want:  "[echo map[] [] ||]\n<p>no close</p>\n",
+		},
+		{
+			name:  "missing closing delimiter",
+			input: "{{< echo a\nrest",
+			want:  "<p>{{&lt; echo a\nrest</p>\n",
+		},
+		{
+			name:  "unterminated quote",
+			input: `{{< echo q="a >}}` + "\nrest",
+			want:  "<p>{{&lt; echo q=&quot;a &gt;}}\nrest</p>\n",
+		},
+		{
+			name:  "close tag alone",
+			input: "{{< /echo >}}",
+			want:  "<p>{{&lt; /echo &gt;}}</p>\n",
+		},
+		{
+			name:  "close tag on same line",
+			input: "{{< echo >}} {{< /echo >}}",
+			want:  "<p>[echo map[] [] ||] {{&lt; /echo &gt;}}</p>\n",
+		},
+		{
+			name:  "empty",
+			input: "{{<>}}",
+			want:  "<p>{{&lt;&gt;}}</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(tt.input), &buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("got  %q\nwant %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package theme
+
+import (
+	"io/fs"
+
+	"github.com/spf13/afero"
+)
+
+// Overlay 把多个只读文件系统叠加为一个：同名文件使用靠前的，目录内容合并
+func Overlay(layers ...fs.FS) fs.FS {
+
+	var result afero.Fs
+	for i := len(layers) - 1; i >= 0; i-- {
+		layer := afero.NewReadOnlyFs(afero.FromIOFS{FS: layers[i]})
+		if result == nil {
+			result = layer
+		} else {
+			result = afero.NewCopyOnWriteFs(result, layer)
+		}
+	}
+
+	if result == nil {
+		return emptyFS{}
+	}
+
+	return afero.NewIOFS(result)
+}
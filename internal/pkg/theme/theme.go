@@ -0,0 +1,237 @@
+package theme
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"mdnav/themes"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultName 内置的默认主题，未配置 theme.name 时使用，其他主题缺少的模板和资源也从这里查找
+const DefaultName = "default"
+
+// 主题目录结构
+const (
+	MetaFile     = "theme.yaml" // 主题信息、默认参数和可用配置项
+	TemplatesDir = "templates"  // 页面模板，结构与 template.dir 相同
+	AssetsDir    = "assets"     // 静态资源，通过 /static 访问
+)
+
+// Theme 主题，可以是主题目录下的文件夹，也可以是 zip 文件
+type Theme struct {
+	Name        string         `yaml:"name" json:"name"`
+	Version     string         `yaml:"version" json:"version"`
+	Description string         `yaml:"description" json:"description"`
+	Author      string         `yaml:"author" json:"author"`
+	Params      map[string]any `yaml:"params" json:"params"`   // site 配置的默认值
+	Options     []Option       `yaml:"options" json:"options"` // 主题接受的 site 配置项
+
+	Source string `yaml:"-" json:"source"` // 主题所在的目录或 zip 文件
+	fsys   fs.FS
+}
+
+// Option 主题声明的配置项
+type Option struct {
+	Name        string   `yaml:"name" json:"name"`
+	Type        string   `yaml:"type" json:"type"` // string、bool、int、float、list、map，为空时不检查类型
+	Enum        []string `yaml:"enum" json:"enum,omitempty"`
+	Description string   `yaml:"description" json:"description"`
+}
+
+// Load 从主题目录加载主题：优先使用 <dir>/<name>/ 文件夹，其次是 <dir>/<name>.zip，都没有时使用同名的内置主题。
+// zip 文件读入内存后立即关闭，所有文件位于同一个顶层目录时以该目录为主题根目录
+func Load(dir, name string) (*Theme, error) {
+
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return nil, fmt.Errorf("无效的主题名称 %q", name)
+	}
+
+	folder := filepath.Join(dir, name)
+	if info, err := os.Stat(folder); err == nil && info.IsDir() {
+		return Open(os.DirFS(folder), folder)
+	}
+
+	archive := folder + ".zip"
+	if _, err := os.Stat(archive); err == nil {
+		data, err := os.ReadFile(archive)
+		if err != nil {
+			return nil, err
+		}
+		r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", archive, err)
+		}
+		return Open(zipRoot(r), archive)
+	}
+
+	if t, err := Builtin(name); err == nil {
+		return t, nil
+	}
+
+	return nil, fmt.Errorf("主题 %s 不存在：%s 和 %s 都没有找到", name, folder, archive)
+}
+
+// Builtin 加载编译进程序的内置主题
+func Builtin(name string) (*Theme, error) {
+
+	fsys, err := fs.Sub(themes.FS, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(fsys, MetaFile); err != nil {
+		return nil, fmt.Errorf("内置主题 %s 不存在", name)
+	}
+
+	return Open(fsys, "内置主题 "+name)
+}
+
+// Open 从文件系统加载主题，source 用于错误信息
+func Open(fsys fs.FS, source string) (*Theme, error) {
+
+	content, err := fs.ReadFile(fsys, MetaFile)
+	if err != nil {
+		return nil, fmt.Errorf("%s: 缺少 %s", source, MetaFile)
+	}
+
+	t := &Theme{Source: source, fsys: fsys}
+	if err := yaml.Unmarshal(content, t); err != nil {
+		return nil, fmt.Errorf("%s/%s: %w", source, MetaFile, err)
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(source), ".zip")
+	}
+
+	// 与 viper 读取的 site 配置一致，参数名不区分大小写
+	params := make(map[string]any, len(t.Params))
+	for k, v := range t.Params {
+		params[strings.ToLower(k)] = v
+	}
+	t.Params = params
+
+	for i, o := range t.Options {
+		if o.Name == "" {
+			return nil, fmt.Errorf("%s/%s: 第 %d 个配置项缺少 name", source, MetaFile, i+1)
+		}
+		t.Options[i].Name = strings.ToLower(o.Name)
+	}
+
+	return t, nil
+}
+
+// Templates 主题的模板目录
+func (t *Theme) Templates() fs.FS {
+	return sub(t.fsys, TemplatesDir)
+}
+
+// Assets 主题的静态资源目录
+func (t *Theme) Assets() fs.FS {
+	return sub(t.fsys, AssetsDir)
+}
+
+// SiteParams 以主题参数为默认值合并 site 配置，并按主题声明的配置项检查类型和取值
+func (t *Theme) SiteParams(site map[string]any) (map[string]any, error) {
+
+	merged := make(map[string]any, len(t.Params)+len(site))
+	for k, v := range t.Params {
+		merged[k] = v
+	}
+	for k, v := range site {
+		merged[k] = v
+	}
+
+	var errs []error
+	for _, o := range t.Options {
+		v, ok := merged[o.Name]
+		if !ok || v == nil {
+			continue
+		}
+		if err := o.check(v); err != nil {
+			errs = append(errs, fmt.Errorf("主题 %s 的配置项 site.%s %w", t.Name, o.Name, err))
+		}
+	}
+
+	return merged, errors.Join(errs...)
+}
+
+func (o Option) check(v any) error {
+
+	ok := true
+	switch o.Type {
+	case "string":
+		_, ok = v.(string)
+	case "bool":
+		_, ok = v.(bool)
+	case "int":
+		switch v.(type) {
+		case int, int64, uint64:
+		default:
+			ok = false
+		}
+	case "float":
+		switch v.(type) {
+		case int, int64, uint64, float64:
+		default:
+			ok = false
+		}
+	case "list":
+		_, ok = v.([]any)
+	case "map":
+		_, ok = v.(map[string]any)
+	}
+	if !ok {
+		return fmt.Errorf("应为 %s 类型，实际为 %T", o.Type, v)
+	}
+
+	if len(o.Enum) > 0 && !slices.Contains(o.Enum, fmt.Sprint(v)) {
+		return fmt.Errorf("只能为 %s，实际为 %v", strings.Join(o.Enum, "、"), v)
+	}
+
+	return nil
+}
+
+// sub 子目录，不存在时返回空文件系统
+func sub(fsys fs.FS, dir string) fs.FS {
+	if info, err := fs.Stat(fsys, dir); err != nil || !info.IsDir() {
+		return emptyFS{}
+	}
+	s, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return emptyFS{}
+	}
+	return s
+}
+
+// zipRoot zip 中没有 theme.yaml 且只有一个顶层目录时，以该目录为根
+func zipRoot(r *zip.Reader) fs.FS {
+
+	if _, err := fs.Stat(r, MetaFile); err == nil {
+		return r
+	}
+
+	entries, err := fs.ReadDir(r, ".")
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return r
+	}
+
+	s, err := fs.Sub(r, path.Clean(entries[0].Name()))
+	if err != nil {
+		return r
+	}
+	return s
+}
+
+type emptyFS struct{}
+
+func (emptyFS) Open(name string) (fs.File, error) {
+	return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+}
@@ -0,0 +1,91 @@
+package theme
+
+import (
+	"archive/zip"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadZip(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		prefix string // zip 中文件的公共目录
+	}{
+		{name: "root", prefix: ""},
+		{name: "top level folder", prefix: "mytheme-1.0/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			dir := t.TempDir()
+			archive := filepath.Join(dir, "mytheme.zip")
+			writeZip(t, archive, map[string]string{
+				tt.prefix + MetaFile:                     "version: 1.0\nparams:\n  Scheme: dark\n",
+				tt.prefix + TemplatesDir + "/index.html": "index",
+				tt.prefix + AssetsDir + "/app.css":       "body{}",
+			})
+
+			theme, err := Load(dir, "mytheme")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// zip 读入内存后即可删除或替换文件
+			if err := os.Remove(archive); err != nil {
+				t.Fatal(err)
+			}
+
+			if theme.Name != "mytheme" || theme.Source != archive || theme.Params["scheme"] != "dark" {
+				t.Errorf("theme = %+v", theme)
+			}
+			if b, err := fs.ReadFile(theme.Templates(), "index.html"); err != nil || string(b) != "index" {
+				t.Errorf("index.html = %q, %v", b, err)
+			}
+			if b, err := fs.ReadFile(theme.Assets(), "app.css"); err != nil || string(b) != "body{}" {
+				t.Errorf("app.css = %q, %v", b, err)
+			}
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.zip"), []byte("not a zip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeZip(t, filepath.Join(dir, "nometa.zip"), map[string]string{"templates/index.html": "index"})
+
+	for _, name := range []string{"", "..", "a/b", "broken", "nometa", "missing"} {
+		if theme, err := Load(dir, name); err == nil {
+			t.Errorf("Load(%q) = %+v, want error", name, theme)
+		}
+	}
+}
+
+func writeZip(t *testing.T, file string, files map[string]string) {
+
+	f, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
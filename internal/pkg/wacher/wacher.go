@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"mdnav/internal/core"
+	"mdnav/internal/pkg/markdown"
 	"mdnav/internal/pkg/zap"
 
 	"github.com/fsnotify/fsnotify"
@@ -29,6 +30,15 @@ func WatcherFile(ctx *core.Context, f func()) {
 		return
 	}
 
+	// 合集目录可选，不存在时不监听
+	if collectionsDir := ctx.Conf.GetString("server.collections_dir"); collectionsDir != "" {
+		if info, err := os.Stat(collectionsDir); err == nil && info.IsDir() {
+			if err := AddWatcherDirRecursive(ctx, watcher, collectionsDir); err != nil {
+				ctx.Log.Error("添加监听目录失败", zap.String("dir", collectionsDir), zap.Error(err))
+			}
+		}
+	}
+
 	// 防抖机制：使用定时器避免短时间内多次触发
 	var debounceTimer *time.Timer
 	debounceDuration := 500 * time.Millisecond // 500ms防抖
@@ -52,8 +62,8 @@ func WatcherFile(ctx *core.Context, f func()) {
 				}
 			}
 
-			// 只处理Markdown文件的变化
-			if path.Ext(event.Name) == ".md" {
+			// 只处理Markdown文件和链接数据文件的变化
+			if path.Ext(event.Name) == ".md" || markdown.IsDataFile(path.Base(event.Name)) {
 				// 取消之前的定时器
 				if debounceTimer != nil {
 					debounceTimer.Stop()
@@ -0,0 +1,109 @@
+package wikilink
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindWikiLink 交叉链接节点
+var KindWikiLink = ast.NewNodeKind("WikiLink")
+
+// Node 交叉链接节点
+type Node struct {
+	ast.BaseInline
+	Target string
+	Label  string
+}
+
+func (n *Node) Kind() ast.NodeKind { return KindWikiLink }
+
+func (n *Node) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Target": n.Target, "Label": n.Label}, nil)
+}
+
+type inlineParser struct{}
+
+func (p *inlineParser) Trigger() []byte {
+	return []byte{'['}
+}
+
+func (p *inlineParser) Parse(parent ast.Node, block text.Reader, pc parser.Context) ast.Node {
+
+	line, _ := block.PeekLine()
+
+	m := refRegex.FindSubmatchIndex(line)
+	if m == nil || m[0] != 0 {
+		return nil
+	}
+
+	node := &Node{Target: string(bytes.TrimSpace(line[m[2]:m[3]]))}
+	if m[4] >= 0 {
+		node.Label = string(bytes.TrimSpace(line[m[4]:m[5]]))
+	}
+
+	block.Advance(m[1])
+	return node
+}
+
+type nodeRenderer struct{}
+
+func (r *nodeRenderer) RegisterFuncs(reg renderer.NodeRendererFuncRegisterer) {
+	reg.Register(KindWikiLink, r.render)
+}
+
+// render 有效的引用输出站内链接，显示文字默认为文档名称，带锚点时链接到文档中的对应位置；
+// 无效的引用输出带 wikilink-broken 的文字
+func (r *nodeRenderer) render(w util.BufWriter, source []byte, node ast.Node, entering bool) (ast.WalkStatus, error) {
+
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+
+	n := node.(*Node)
+	base, anchor := SplitAnchor(n.Target)
+	href, title, ok := resolve(base)
+	if ok && anchor != "" {
+		href += "#" + anchor
+	}
+
+	label := n.Label
+	if label == "" {
+		label = n.Target
+		if ok && title != "" {
+			label = title
+		}
+	}
+
+	if !ok {
+		w.WriteString(`<span class="wikilink wikilink-broken" title="`)
+		w.Write(util.EscapeHTML([]byte("无效的引用：" + n.Target)))
+		w.WriteString(`">`)
+		w.Write(util.EscapeHTML([]byte(label)))
+		w.WriteString(`</span>`)
+		return ast.WalkSkipChildren, nil
+	}
+
+	w.WriteString(`<a class="wikilink" href="`)
+	w.Write(util.EscapeHTML(util.URLEscape([]byte(href), true)))
+	w.WriteString(`">`)
+	w.Write(util.EscapeHTML([]byte(label)))
+	w.WriteString(`</a>`)
+
+	return ast.WalkSkipChildren, nil
+}
+
+type extension struct{}
+
+// Extension 交叉链接的 goldmark 扩展
+var Extension goldmark.Extender = &extension{}
+
+func (e *extension) Extend(m goldmark.Markdown) {
+	m.Parser().AddOptions(parser.WithInlineParsers(util.Prioritized(&inlineParser{}, 150)))
+	m.Renderer().AddOptions(renderer.WithNodeRenderers(util.Prioritized(&nodeRenderer{}, 150)))
+}
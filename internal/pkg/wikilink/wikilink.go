@@ -0,0 +1,134 @@
+// Package wikilink 文档正文中的 [[分类/slug]]、[[名称]]、[[目标#锚点]] 和 [[目标|显示文字]] 交叉链接
+package wikilink
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound 没有匹配的文档
+var ErrNotFound = errors.New("没有匹配的文档")
+
+// Ref 正文中的一处引用
+type Ref struct {
+	Target string // 引用目标，slug 或文档名称
+	Label  string // 显示文字，未指定时为空
+	Line   int    // 在正文中的行号，从 1 开始
+}
+
+var (
+	refRegex  = regexp.MustCompile(`\[\[([^\[\]|\n]+?)(?:\|([^\[\]\n]+?))?\]\]`)
+	codeRegex = regexp.MustCompile("`[^`\n]*`")
+)
+
+// Extract 提取正文中的引用，忽略代码块和行内代码
+func Extract(markdown string) []Ref {
+
+	var refs []Ref
+	fence := ""
+
+	for i, line := range strings.Split(markdown, "\n") {
+
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		line = codeRegex.ReplaceAllString(line, "")
+		for _, m := range refRegex.FindAllStringSubmatch(line, -1) {
+			refs = append(refs, Ref{Target: strings.TrimSpace(m[1]), Label: strings.TrimSpace(m[2]), Line: i + 1})
+		}
+	}
+
+	return refs
+}
+
+// Index 按 slug 和名称查找文档，均不区分大小写
+type Index struct {
+	slugs map[string]string   // 小写 slug -> slug
+	names map[string][]string // 小写名称 -> slug
+}
+
+func NewIndex() *Index {
+	return &Index{
+		slugs: make(map[string]string),
+		names: make(map[string][]string),
+	}
+}
+
+// Add 添加文档
+func (x *Index) Add(slug, name string) {
+	x.slugs[strings.ToLower(slug)] = slug
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		x.names[name] = append(x.names[name], slug)
+	}
+}
+
+// Resolve 解析引用目标，先按 slug（可以带开头的 / 和 .md 后缀）匹配，再按名称匹配，# 之后的锚点不参与匹配；
+// 名称对应多个文档时返回错误，需要改用 slug
+func (x *Index) Resolve(target string) (string, error) {
+
+	target, _ = SplitAnchor(target)
+
+	key := strings.ToLower(strings.TrimSuffix(strings.Trim(strings.TrimSpace(target), "/"), ".md"))
+	if key == "" {
+		return "", ErrNotFound
+	}
+
+	if slug, ok := x.slugs[key]; ok {
+		return slug, nil
+	}
+
+	slugs := x.names[strings.ToLower(strings.TrimSpace(target))]
+	switch len(slugs) {
+	case 0:
+		return "", ErrNotFound
+	case 1:
+		return slugs[0], nil
+	}
+
+	return "", fmt.Errorf("名称对应多个文档：%s，请改用 slug", strings.Join(slugs, "、"))
+}
+
+// SplitAnchor 拆分引用目标中 # 之后的锚点，如 dev/github#安装 拆分为 dev/github 和 安装
+func SplitAnchor(target string) (base, anchor string) {
+	base, anchor, _ = strings.Cut(target, "#")
+	return strings.TrimSpace(base), strings.TrimSpace(anchor)
+}
+
+// Resolver 渲染时把引用目标转换为链接地址和文档名称，ok 为 false 时按无效引用显示
+type Resolver func(target string) (href, title string, ok bool)
+
+var (
+	mx       sync.RWMutex
+	resolver Resolver
+)
+
+// SetResolver 设置渲染时使用的解析函数
+func SetResolver(r Resolver) {
+	mx.Lock()
+	defer mx.Unlock()
+	resolver = r
+}
+
+func resolve(target string) (href, title string, ok bool) {
+
+	mx.RLock()
+	r := resolver
+	mx.RUnlock()
+
+	if r == nil {
+		return "", "", false
+	}
+	return r(target)
+}
@@ -0,0 +1,173 @@
+package wikilink
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yuin/goldmark"
+)
+
+func TestExtract(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		markdown string
+		want     []Ref
+	}{
+		{
+			name:     "slug and name",
+			markdown: "see [[dev/github]] and [[ Visual Studio ]]",
+			want:     []Ref{{Target: "dev/github", Line: 1}, {Target: "Visual Studio", Line: 1}},
+		},
+		{
+			name:     "alias",
+			markdown: "line\n[[dev/github|代码托管]]",
+			want:     []Ref{{Target: "dev/github", Label: "代码托管", Line: 2}},
+		},
+		{
+			name:     "anchor",
+			markdown: "[[dev/github#安装]] [[GitHub#usage|用法]]",
+			want:     []Ref{{Target: "dev/github#安装", Line: 1}, {Target: "GitHub#usage", Label: "用法", Line: 1}},
+		},
+		{
+			name:     "code is ignored",
+			markdown: "`[[a]]` [[b]]\n```\n[[c]]\n```\n~~~go\n[[d]]\n~~~\n[[e]]",
+			want:     []Ref{{Target: "b", Line: 1}, {Target: "e", Line: 8}},
+		},
+		{
+			name:     "not a reference",
+			markdown: "[[]] [[a\nb]] [[x[y]]] [link](url)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.markdown); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIndexResolve(t *testing.T) {
+
+	index := NewIndex()
+	index.Add("dev/github", "GitHub")
+	index.Add("dev/gitlab", "GitLab")
+	index.Add("tools/notion", "Notes")
+	index.Add("ai/notes", "Notes")
+
+	tests := []struct {
+		target  string
+		want    string
+		err     error // 为 nil 且 want 为空时期望其他错误
+		errText string
+	}{
+		{target: "dev/github", want: "dev/github"},
+		{target: "/Dev/GitHub.md", want: "dev/github"},
+		{target: "github", want: "dev/github"},
+		{target: " GITLAB ", want: "dev/gitlab"},
+		{target: "dev/github#安装", want: "dev/github"},
+		{target: "GitHub#usage", want: "dev/github"},
+		{target: "ai/notes", want: "ai/notes"},
+		{target: "Notes", errText: "名称对应多个文档"},
+		{target: "missing", err: ErrNotFound},
+		{target: "#only-anchor", err: ErrNotFound},
+		{target: " / ", err: ErrNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got, err := index.Resolve(tt.target)
+			switch {
+			case tt.want != "":
+				if err != nil || got != tt.want {
+					t.Errorf("Resolve(%q) = %q, %v, want %q", tt.target, got, err, tt.want)
+				}
+			case tt.err != nil:
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Resolve(%q) error = %v, want %v", tt.target, err, tt.err)
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), tt.errText) {
+					t.Errorf("Resolve(%q) error = %v, want containing %q", tt.target, err, tt.errText)
+				}
+			}
+		})
+	}
+}
+
+func TestExtension(t *testing.T) {
+
+	index := NewIndex()
+	index.Add("dev/github", "GitHub")
+	index.Add("dev/a&b", "A & B")
+
+	SetResolver(func(target string) (href, title string, ok bool) {
+		slug, err := index.Resolve(target)
+		if err != nil {
+			return "", "", false
+		}
+		return "/article/" + slug, map[string]string{"dev/github": "GitHub", "dev/a&b": "A & B"}[slug], true
+	})
+	defer SetResolver(nil)
+
+	md := goldmark.New(goldmark.WithExtensions(Extension))
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "name as label",
+			input: "[[dev/github]]",
+			want:  `<p><a class="wikilink" href="/article/dev/github">GitHub</a></p>`,
+		},
+		{
+			name:  "alias",
+			input: "[[github|代码托管]]",
+			want:  `<p><a class="wikilink" href="/article/dev/github">代码托管</a></p>`,
+		},
+		{
+			name:  "anchor",
+			input: "[[dev/github#安装]]",
+			want:  `<p><a class="wikilink" href="/article/dev/github#%E5%AE%89%E8%A3%85">GitHub</a></p>`,
+		},
+		{
+			name:  "anchor and alias",
+			input: "[[GitHub#usage|用法]]",
+			want:  `<p><a class="wikilink" href="/article/dev/github#usage">用法</a></p>`,
+		},
+		{
+			name:  "escaped",
+			input: "[[A & B]]",
+			want:  `<p><a class="wikilink" href="/article/dev/a&amp;b">A &amp; B</a></p>`,
+		},
+		{
+			name:  "broken",
+			input: "[[missing#x|<b>]]",
+			want:  `<p><span class="wikilink wikilink-broken" title="无效的引用：missing#x">&lt;b&gt;</span></p>`,
+		},
+		{
+			name:  "inline code",
+			input: "`[[dev/github]]`",
+			want:  `<p><code>[[dev/github]]</code></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := md.Convert([]byte(tt.input), &buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.TrimSpace(buf.String()); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
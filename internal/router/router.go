@@ -14,6 +14,7 @@ import (
 	"mdnav/internal/middleware"
 	"mdnav/internal/pkg/zap"
 	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,11 +30,14 @@ func Run(ctx *core.Context) {
 	router.Use(middleware.Options(ctx))
 
 	h := &handler.Handler{
-		Ctx:    ctx,
-		TplDir: ctx.Conf.GetString("template.dir"),
+		Ctx: ctx,
 	}
 
-	router.Static("/static", ctx.Conf.GetString("template.static_dir"))
+	// 静态资源：template.static_dir 中的文件优先，其次是当前主题的 assets
+	router.StaticFS("/static", http.FS(tpl.Assets))
+	// 与文档放在同一目录中的资源文件，和静态资源一样不限速
+	router.GET("/bundle/*path", h.Bundle)
+	router.HEAD("/bundle/*path", h.Bundle)
 
 	authorized := router.Group("/system", gin.BasicAuth(gin.Accounts{
 		"admin-manger": "admin-oaeoe-password",
@@ -43,18 +47,51 @@ func Run(ctx *core.Context) {
 		if err := conf.InitConfig(".", "config", "false"); err != nil {
 			h.Ctx.Log.Error("加载配置出错", zap.Error(err))
 		}
+		// 先加载主题和模板，预先渲染文档时才能使用新主题中的短代码
+		if err := service.LoadTheme(h.Ctx); err != nil {
+			h.Ctx.Log.Error("加载主题出错", zap.Error(err))
+		} else if err := tpl.Reload(); err != nil {
+			h.Ctx.Log.Error("编译模板出错", zap.Error(err))
+		}
 		if err := service.LoadAllData(h.Ctx); err != nil {
 			h.Ctx.Log.Error("加载数据出错", zap.Error(err))
 		}
 		c.AbortWithStatus(200)
 	})
+	authorized.GET("/scheduled", h.SystemScheduled)
+	authorized.GET("/documents", h.SystemDocuments)
+	authorized.GET("/links", h.SystemLinks)
+	authorized.POST("/links", h.SystemSaveLink)
+	authorized.DELETE("/links", h.SystemDeleteLink)
+	authorized.GET("/links/broken", h.SystemBrokenLinks)
+	authorized.POST("/links/check", h.SystemCheckLinks)
+	authorized.GET("/wikilinks/broken", h.SystemBrokenWikiLinks)
+	authorized.POST("/new", h.SystemNewLink)
+	authorized.POST("/import/:format", h.SystemImport)
+	authorized.GET("/export/:format", h.SystemExport)
 
 	r := router.Group("").Use(middleware.IpRateLimiter(ctx))
 	r.GET("/", h.Index)
+	r.GET("/latest", h.Latest)
+	r.GET("/updated", h.Updated)
 
 	r.GET("/:slug", h.Category)
 	r.GET("/tag/:tagName", h.Tag)
 	r.GET("/article/*slug", h.Article)
+	r.GET("/collection/*slug", h.Collection)
+	r.GET("/preview/*slug", h.Preview)
+	r.GET("/icons/:host", h.Icon)
+
+	// 其余路径为 pages_dir 下的独立页面
+	router.NoRoute(middleware.IpRateLimiter(ctx), h.Page)
+
+	api := router.Group("/api").Use(middleware.IpRateLimiter(ctx))
+	api.GET("/documents", h.ApiDocuments)
+	api.GET("/categories", h.ApiCategories)
+	api.GET("/tags", h.ApiTags)
+	api.GET("/latest", h.ApiLatest)
+	api.GET("/updated", h.ApiUpdated)
+	api.GET("/collection/*slug", h.ApiCollection)
 
 	serverPort := ctx.Conf.GetString("server.port")
 	srv := &http.Server{
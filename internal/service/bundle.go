@@ -0,0 +1,23 @@
+package service
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/bundle"
+	"mdnav/internal/pkg/markdown"
+)
+
+// OpenBundleFile 打开 content_dir 中与文档放在一起的资源文件，md 文件和链接数据文件不作为资源提供
+func OpenBundleFile(ctx *core.Context, name string) (*os.File, fs.FileInfo, error) {
+
+	base := path.Base(name)
+	if strings.EqualFold(path.Ext(base), ".md") || markdown.IsDataFile(base) {
+		return nil, nil, bundle.ErrNotFound
+	}
+
+	return bundle.Open(ctx.Conf.GetString("server.content_dir"), name)
+}
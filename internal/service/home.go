@@ -0,0 +1,121 @@
+package service
+
+import (
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+)
+
+// 首页区块名称
+const (
+	SectionFeatured   = "featured"   // 推荐
+	SectionRecent     = "recent"     // 最近添加
+	SectionPopular    = "popular"    // 热门，按排序权重
+	SectionCategories = "categories" // 分类列表
+)
+
+// 默认首页布局
+var defaultHomeSections = []string{SectionFeatured, SectionCategories}
+
+var sectionTitles = map[string]string{
+	SectionFeatured:   "推荐",
+	SectionRecent:     "最近添加",
+	SectionPopular:    "热门",
+	SectionCategories: "分类",
+}
+
+// HomeSection 首页区块
+type HomeSection struct {
+	Name       string              `json:"name"`
+	Title      string              `json:"title"`
+	Documents  []doc.Document      `json:"documents,omitempty"`
+	Categories []CategoryDocuments `json:"categories,omitempty"`
+}
+
+// GetFeaturedDocuments 获取推荐文档，按排序权重降序；
+// rotate 大于0且推荐数量超过 limit 时，每隔 rotate 时间轮换展示下一批
+func GetFeaturedDocuments(limit int, rotate time.Duration, now time.Time) []doc.Document {
+
+	s := current()
+	var featured []doc.Document
+	for _, d := range s.documents.GetDocumentsSlice() {
+		if d.IsFeatured(now) {
+			featured = append(featured, d)
+		}
+	}
+
+	featured = doc.SortDocuments(featured, doc.SortBySort, doc.Descending)
+
+	if limit <= 0 || len(featured) <= limit {
+		return featured
+	}
+
+	if rotate <= 0 {
+		return featured[:limit]
+	}
+
+	period := int64(rotate / time.Second)
+	if period < 1 {
+		period = 1
+	}
+	offset := int((now.Unix() / period * int64(limit)) % int64(len(featured)))
+
+	result := make([]doc.Document, 0, limit)
+	for i := 0; i < limit; i++ {
+		result = append(result, featured[(offset+i)%len(featured)])
+	}
+
+	return result
+}
+
+// GetHomeSections 根据 home.sections 配置生成首页区块，顺序与配置一致；
+// 带过滤表达式时只展示过滤后的分类列表，推荐、最近添加等区块不参与过滤，隐藏以免与搜索结果矛盾
+func GetHomeSections(ctx *core.Context, categoryDocuments []CategoryDocuments, q *doc.Query) []HomeSection {
+
+	names := ctx.Conf.GetStringSlice("home.sections")
+	if len(names) == 0 {
+		names = defaultHomeSections
+	}
+	if !q.IsEmpty() {
+		names = []string{SectionCategories}
+	}
+
+	now := time.Now()
+
+	var sections []HomeSection
+	for _, name := range names {
+
+		section := HomeSection{Name: name, Title: sectionTitles[name]}
+		if title := ctx.Conf.GetString("home." + name + ".title"); title != "" {
+			section.Title = title
+		}
+
+		limit := ctx.Conf.GetInt("home." + name + ".limit")
+		if limit <= 0 {
+			limit = 8
+		}
+
+		switch name {
+		case SectionFeatured:
+			section.Documents = GetFeaturedDocuments(limit, ctx.Conf.GetDuration("home.featured.rotate"), now)
+		case SectionRecent:
+			section.Documents = GetPageDocuments(1, limit, doc.SortByCreateTime, doc.Descending).Documents
+		case SectionPopular:
+			section.Documents = GetPageDocuments(1, limit, doc.SortBySort, doc.Descending).Documents
+		case SectionCategories:
+			section.Categories = categoryDocuments
+		default:
+			ctx.Log.Warn("未知的首页区块：" + name)
+			continue
+		}
+
+		if len(section.Documents) == 0 && len(section.Categories) == 0 {
+			continue
+		}
+
+		sections = append(sections, section)
+	}
+
+	return sections
+}
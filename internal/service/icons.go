@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/favicon"
+	"mdnav/internal/pkg/zap"
+	"mdnav/internal/utils"
+)
+
+var iconResolver *favicon.Resolver // 网站图标缓存，未启用 icons.enabled 时为 nil
+
+// InitIcons 初始化网站图标缓存，未启用 icons.enabled 时模板直接使用文档的 icon
+func InitIcons(ctx *core.Context) {
+
+	if !ctx.Conf.GetBool("icons.enabled") {
+		return
+	}
+
+	r, err := favicon.New(favicon.Options{
+		Dir:        ctx.Conf.GetString("icons.dir"),
+		Size:       ctx.Conf.GetInt("icons.size"),
+		Timeout:    ctx.Conf.GetDuration("icons.timeout"),
+		MaxAge:     ctx.Conf.GetDuration("icons.max_age"),
+		MaxEntries: ctx.Conf.GetInt("icons.max_entries"),
+		Offline:    ctx.Conf.GetBool("icons.offline"),
+	})
+	if err != nil {
+		ctx.Log.Error("图标缓存初始化失败", zap.Error(err))
+		return
+	}
+
+	iconResolver = r
+}
+
+// IconURL 文档图标的地址：没有 icon 时为网站图标 /icons/<域名>，
+// icon 为远程地址时通过 /icons/<图标域名>?src=<短代码> 代理，本地路径原样返回
+func IconURL(d doc.Document) string {
+
+	if iconResolver == nil {
+		return d.Icon
+	}
+
+	if d.Icon == "" {
+		if host := urlHost(d.Url); host != "" {
+			return "/icons/" + host
+		}
+		return ""
+	}
+
+	if host := urlHost(d.Icon); host != "" {
+		return "/icons/" + host + "?src=" + utils.GenerateShortCode(d.Icon)
+	}
+
+	return d.Icon
+}
+
+// iconSources /icons/ 可以获取的图标：域名 -> 文档地址（获取网站图标），域名?src=短代码 -> 远程 icon 地址
+type iconSources map[string]string
+
+// buildIconSources 按可见文档生成图标地址索引，草稿、隐藏和不在发布窗口内的文档不参与
+func buildIconSources(docs *doc.DocumentsMap) iconSources {
+
+	sources := make(iconSources)
+	if docs == nil {
+		return sources
+	}
+
+	for _, d := range docs.GetDocumentsMap() {
+		if d.Icon != "" {
+			if host := urlHost(d.Icon); host != "" {
+				sources[host+"?src="+utils.GenerateShortCode(d.Icon)] = d.Icon
+			}
+			continue
+		}
+		if host := urlHost(d.Url); host != "" {
+			if _, ok := sources[host]; !ok {
+				sources[host] = d.Url
+			}
+		}
+	}
+
+	return sources
+}
+
+// ResolveIcon 返回图标缓存文件。只处理可见文档的域名和图标地址，避免被用来请求任意地址；
+// src 为远程 icon 地址的短代码，为空时获取域名的网站图标
+func ResolveIcon(ctx context.Context, host, src string) (string, error) {
+
+	r := iconResolver
+	if r == nil {
+		return "", favicon.ErrNotFound
+	}
+
+	host = strings.ToLower(host)
+	sources := current().icons
+
+	if src != "" {
+		if icon, ok := sources[host+"?src="+src]; ok {
+			return r.Remote(ctx, "src-"+src, icon)
+		}
+		return "", favicon.ErrNotFound
+	}
+
+	if pageUrl, ok := sources[host]; ok {
+		return r.Site(ctx, host, pageUrl)
+	}
+
+	return "", favicon.ErrNotFound
+}
+
+// EvictIcons 按数量上限清理图标缓存，返回是否启用了缓存
+func EvictIcons() bool {
+	if r := iconResolver; r != nil {
+		r.Evict()
+		return true
+	}
+	return false
+}
+
+// urlHost http(s) 地址的域名（小写，含端口），其他地址返回空
+func urlHost(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// PrefetchIcons 获取可见文档的图标并写入缓存，返回成功和失败的数量
+func PrefetchIcons(ctx context.Context) (fetched, failed int) {
+
+	docs := current().documents
+	if iconResolver == nil || docs == nil {
+		return 0, 0
+	}
+
+	seen := make(map[string]bool)
+	for _, d := range docs.GetDocumentsMap() {
+		u, err := url.Parse(IconURL(d))
+		if err != nil || !strings.HasPrefix(u.Path, "/icons/") {
+			continue
+		}
+		host, src := strings.TrimPrefix(u.Path, "/icons/"), u.Query().Get("src")
+		if seen[host+"?"+src] {
+			continue
+		}
+		seen[host+"?"+src] = true
+
+		if _, err := ResolveIcon(ctx, host, src); err != nil {
+			failed++
+		} else {
+			fetched++
+		}
+	}
+
+	return fetched, failed
+}
@@ -0,0 +1,414 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mdnav/internal/core"
+	"mdnav/internal/lint"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/exchange"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/utils"
+)
+
+// ImportOptions 导入选项
+type ImportOptions struct {
+	DryRun bool // 只生成报告，不写入任何文件
+	Update bool // url 已存在时按导入数据更新文档，否则跳过
+}
+
+// ImportReport 导入结果，DryRun 时为将要执行的变更
+type ImportReport struct {
+	DryRun     bool          `json:"dry_run"`
+	Categories []string      `json:"categories"` // 新建的分类
+	Created    []ImportEntry `json:"created"`    // 新建的文档
+	Updated    []ImportEntry `json:"updated"`    // 更新的文档，Reason 为修改的字段
+	Skipped    []ImportEntry `json:"skipped"`    // 跳过的链接
+}
+
+// ImportEntry 导入的单个链接
+type ImportEntry struct {
+	Slug   string `json:"slug,omitempty"`
+	Name   string `json:"name"`
+	Url    string `json:"url"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// importer 一次导入过程的状态
+type importer struct {
+	contentDir string
+	dir        *exchange.Directory
+	opts       ImportOptions
+	report     *ImportReport
+	cateDirs   map[string]string // 导入的分类 slug -> 实际写入的目录
+	planned    map[string]bool   // 本次将要创建的文件，DryRun 时用于避免重名
+}
+
+// ImportDirectory 把导入的目录写入内容目录：每个新链接生成一个 md 文件，
+// 所在分类目录缺少 _index.md 时自动生成。url 已存在（忽略协议、www 和末尾斜杠）的链接按 opts.Update 更新或跳过，
+// 有变更时重新加载数据
+func ImportDirectory(ctx *core.Context, dir *exchange.Directory, opts ImportOptions) (*ImportReport, error) {
+
+	im := newImporter(ctx, dir, opts)
+	report := im.report
+
+	existing := documentsByUrl()
+	seen := make(map[string]bool)
+
+	for _, e := range dir.Entries {
+
+		entry := ImportEntry{Name: e.Name, Url: e.Url}
+
+		key := utils.NormalizeUrl(e.Url)
+		if seen[key] {
+			entry.Reason = "导入数据中 url 重复"
+			report.Skipped = append(report.Skipped, entry)
+			continue
+		}
+		seen[key] = true
+
+		if d, ok := existing[key]; ok {
+			entry.Slug = d.Slug
+			if !opts.Update {
+				entry.Reason = "url 已存在"
+				report.Skipped = append(report.Skipped, entry)
+				continue
+			}
+
+			fields, values := changedFields(d, e, dir.Fields)
+			if len(fields) == 0 {
+				entry.Reason = "没有变化"
+				report.Skipped = append(report.Skipped, entry)
+				continue
+			}
+
+			if !opts.DryRun {
+				if err := updateDocument(d, values); err != nil {
+					return report, err
+				}
+			}
+
+			entry.Reason = strings.Join(fields, ", ")
+			report.Updated = append(report.Updated, entry)
+			continue
+		}
+
+		cateSlug, err := im.ensureCategory(e.Category)
+		if err != nil {
+			return report, err
+		}
+
+		if entry.Slug, err = im.writeEntry(cateSlug, e); err != nil {
+			return report, err
+		}
+
+		report.Created = append(report.Created, entry)
+	}
+
+	if opts.DryRun || len(report.Created)+len(report.Updated)+len(report.Categories) == 0 {
+		return report, nil
+	}
+
+	return report, LoadAllData(ctx)
+}
+
+func newImporter(ctx *core.Context, dir *exchange.Directory, opts ImportOptions) *importer {
+	return &importer{
+		contentDir: ctx.Conf.GetString("server.content_dir"),
+		dir:        dir,
+		opts:       opts,
+		report: &ImportReport{
+			DryRun:     opts.DryRun,
+			Categories: []string{},
+			Created:    []ImportEntry{},
+			Updated:    []ImportEntry{},
+			Skipped:    []ImportEntry{},
+		},
+		cateDirs: make(map[string]string),
+		planned:  make(map[string]bool),
+	}
+}
+
+// documentsByUrl 全部文档按规范化的 url 索引
+func documentsByUrl() map[string]doc.Document {
+	existing := make(map[string]doc.Document)
+	if docs := current().allDocuments; docs != nil {
+		for _, d := range docs.GetDocumentsMap() {
+			existing[utils.NormalizeUrl(d.Url)] = d
+		}
+	}
+	return existing
+}
+
+// ExportDirectory 把当前可见的分类和文档转换为导出目录
+func ExportDirectory() *exchange.Directory {
+
+	dir := &exchange.Directory{}
+
+	for _, v := range GetCategoriesDocuments(doc.SortBySort, doc.Descending) {
+		dir.AddCategory(exchange.Category{
+			Slug:        v.Category.Slug,
+			Name:        v.Category.Name,
+			Description: v.Category.Description,
+			Sort:        v.Category.Sort,
+		})
+		for _, d := range v.DocumentList {
+			dir.Entries = append(dir.Entries, exchange.Entry{
+				Category:    d.CateSlug,
+				Name:        d.Name,
+				Url:         d.Url,
+				Description: d.Description,
+				Tags:        d.Tags,
+				Icon:        d.Icon,
+				Sort:        d.Sort,
+				CreateTime:  d.CreateTime,
+			})
+		}
+	}
+
+	return dir
+}
+
+// ensureCategory 确保分类目录存在，返回实际使用的分类 slug
+func (im *importer) ensureCategory(slug string) (string, error) {
+
+	if v, ok := im.cateDirs[slug]; ok {
+		return v, nil
+	}
+
+	target, err := importCategorySlug(slug)
+	if err != nil {
+		return "", err
+	}
+
+	if err := im.writeCategoryIndex(target, slug); err != nil {
+		return "", err
+	}
+
+	im.cateDirs[slug] = target
+	return target, nil
+}
+
+// importCategorySlug 校验分类路径，一级目录与保留路由重名时追加 -links
+func importCategorySlug(slug string) (string, error) {
+
+	slug = strings.Trim(path.Clean("/"+strings.TrimSpace(slug)), "/")
+	if slug == "" {
+		return "", errors.New("分类不能为空")
+	}
+
+	parts := strings.Split(slug, "/")
+	for _, v := range lint.ReservedSlugs {
+		if strings.EqualFold(parts[0], v) {
+			parts[0] += "-links"
+			break
+		}
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+// writeCategoryIndex 创建分类目录及 _index.md，已存在时不覆盖，多级分类的上级目录同样处理，
+// origin 为导入数据中的分类 slug，用于读取分类名称等信息
+func (im *importer) writeCategoryIndex(slug, origin string) error {
+
+	if parent := path.Dir(slug); parent != "." {
+		if err := im.writeCategoryIndex(parent, path.Dir(origin)); err != nil {
+			return err
+		}
+	}
+
+	cateDir := filepath.Join(im.contentDir, filepath.FromSlash(slug))
+	indexFile := filepath.Join(cateDir, "_index.md")
+	if im.planned[indexFile] || utils.PathExist(indexFile) {
+		return nil
+	}
+
+	im.planned[indexFile] = true
+	im.report.Categories = append(im.report.Categories, slug)
+	if im.opts.DryRun {
+		return nil
+	}
+
+	if err := os.MkdirAll(cateDir, 0755); err != nil {
+		return err
+	}
+
+	md := markdown.Markdown{Name: im.dir.CategoryName(origin), Published: true}
+	for _, c := range im.dir.Categories {
+		if c.Slug == origin {
+			md.Description = c.Description
+			md.Sort = c.Sort
+		}
+	}
+
+	content, err := markdown.Marshal(md)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(indexFile, content, 0644)
+}
+
+// writeEntry 把链接写入分类目录下的 md 文件，文件名由名称生成
+func (im *importer) writeEntry(cateSlug string, e exchange.Entry) (string, error) {
+
+	base := utils.Slugify(e.Name)
+	if base == "" {
+		base = "link-" + utils.GenerateShortCode(strings.TrimSpace(e.Url))
+	}
+
+	return im.writeDocument(cateSlug, base, markdown.Markdown{
+		Name:        e.Name,
+		Description: e.Description,
+		Published:   true,
+		Sort:        e.Sort,
+		Icon:        e.Icon,
+		Url:         e.Url,
+		Tags:        e.Tags,
+		CreateTime:  e.CreateTime,
+	})
+}
+
+// writeDocument 把文档写入分类目录下的 base.md，重名时追加序号，返回文档 slug
+func (im *importer) writeDocument(cateSlug, base string, md markdown.Markdown) (string, error) {
+
+	name := base
+	file := filepath.Join(im.contentDir, filepath.FromSlash(cateSlug), name+".md")
+	for i := 2; im.planned[file] || utils.PathExist(file); i++ {
+		name = base + "-" + strconv.Itoa(i)
+		file = filepath.Join(im.contentDir, filepath.FromSlash(cateSlug), name+".md")
+	}
+	im.planned[file] = true
+
+	if im.opts.DryRun {
+		return path.Join(cateSlug, name), nil
+	}
+
+	content, err := markdown.Marshal(md)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return "", err
+	}
+
+	return path.Join(cateSlug, name), nil
+}
+
+// changedFields 比较已有文档与导入数据，只比较导入数据包含的字段，
+// 返回有变化的字段及写入 front matter 的新值（空值为 nil，表示删除该字段）
+func changedFields(d doc.Document, e exchange.Entry, fields map[string]bool) ([]string, map[string]any) {
+
+	var changed []string
+	values := make(map[string]any)
+
+	set := func(field string, value any, empty bool) {
+		changed = append(changed, field)
+		if empty {
+			values[field] = nil
+		} else {
+			values[field] = value
+		}
+	}
+
+	if fields[exchange.FieldName] && e.Name != d.Name {
+		set(exchange.FieldName, e.Name, false)
+	}
+	if fields[exchange.FieldDescription] && e.Description != d.Description {
+		set(exchange.FieldDescription, e.Description, e.Description == "")
+	}
+	if fields[exchange.FieldIcon] && e.Icon != d.Icon {
+		set(exchange.FieldIcon, e.Icon, e.Icon == "")
+	}
+	if fields[exchange.FieldSort] && e.Sort != d.Sort {
+		set(exchange.FieldSort, e.Sort, e.Sort == 0)
+	}
+	if fields[exchange.FieldTags] {
+		tags := append([]string(nil), e.Tags...)
+		sort.Strings(tags)
+		if !slices.Equal(tags, d.Tags) && len(tags)+len(d.Tags) > 0 {
+			set(exchange.FieldTags, e.Tags, len(e.Tags) == 0)
+		}
+	}
+
+	return changed, values
+}
+
+// updateDocument 按字段修改文档所在的 md 文件或链接数据文件
+func updateDocument(d doc.Document, values map[string]any) error {
+
+	if markdown.IsDataFile(filepath.Base(d.Source)) {
+		return updateDataRecord(d, values)
+	}
+
+	content, err := os.ReadFile(d.Source)
+	if err != nil {
+		return err
+	}
+
+	content, err = markdown.UpdateFrontMatter(content, values)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(d.Source, content, 0644)
+}
+
+func updateDataRecord(d doc.Document, values map[string]any) error {
+
+	records, err := markdown.ParseDataFile(d.Source)
+	if err != nil {
+		return err
+	}
+
+	slug := path.Base(d.Slug)
+	for i, r := range records {
+		if doc.RecordSlug(r) != slug {
+			continue
+		}
+		for field, value := range values {
+			switch field {
+			case exchange.FieldName:
+				r.Name, _ = value.(string)
+			case exchange.FieldDescription:
+				r.Description, _ = value.(string)
+			case exchange.FieldIcon:
+				r.Icon, _ = value.(string)
+			case exchange.FieldSort:
+				r.Sort, _ = value.(int)
+			case exchange.FieldTags:
+				r.Tags, _ = value.([]string)
+			}
+		}
+		records[i] = r
+		return markdown.WriteDataFile(d.Source, records)
+	}
+
+	return os.ErrNotExist
+}
+
+// CsvMapping 读取 exchange.csv_columns 配置的 CSV 列映射，columns（name=标题,url=链接）中的映射优先
+func CsvMapping(ctx *core.Context, columns string) (exchange.CsvMapping, error) {
+
+	mapping, err := exchange.ParseCsvMapping(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	for field, column := range ctx.Conf.GetStringMapString("exchange.csv_columns") {
+		if _, ok := mapping[field]; !ok && column != "" {
+			mapping[field] = column
+		}
+	}
+
+	return mapping, nil
+}
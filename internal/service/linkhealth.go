@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/linkcheck"
+	"mdnav/internal/pkg/zap"
+)
+
+var linkState atomic.Pointer[linkHealth] // 链接检查结果，未启用检查时为 nil
+var linkCheckMx sync.Mutex               // 同一时间只运行一次检查
+var linkCheckCh = make(chan struct{}, 1)
+
+// linkHealth 链接检查结果及自动隐藏的时长，打开结果文件后一起替换，
+// 访客请求和快照刷新读取时不需要加锁
+type linkHealth struct {
+	store     *linkcheck.Store
+	hideAfter time.Duration // 连续失败超过该时长的文档自动隐藏，0 表示不隐藏
+}
+
+// BrokenLink 失效链接报告中的一条
+type BrokenLink struct {
+	Slug      string           `json:"slug"`
+	Name      string           `json:"name"`
+	Url       string           `json:"url"`
+	Source    string           `json:"source"`
+	Hidden    bool             `json:"hidden"` // 是否已被自动隐藏
+	Health    linkcheck.Health `json:"health"`
+	DownHours int              `json:"down_hours"`
+}
+
+// StartLinkChecker 启动链接检查协程，按 linkcheck.interval 周期检查所有文档的 url，
+// 未启用 linkcheck.enabled 时不启动
+func StartLinkChecker(ctx *core.Context) {
+
+	if !ctx.Conf.GetBool("linkcheck.enabled") {
+		return
+	}
+
+	if err := openLinkStore(ctx); err != nil {
+		ctx.Log.Error("链接检查结果加载失败", zap.Error(err))
+		return
+	}
+
+	// 按已保存的结果隐藏长期失效的链接
+	refreshSnapshot(time.Now())
+
+	interval := ctx.Conf.GetDuration("linkcheck.interval")
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	go func() {
+		// 距上次检查不足一个周期时等到周期结束，避免每次重启都检查
+		delay := 10 * time.Second
+		if last := lastCheckTime(); !last.IsZero() {
+			delay = max(delay, time.Until(last.Add(interval)))
+		}
+		timer := time.NewTimer(delay)
+
+		for {
+			select {
+			case <-timer.C:
+			case <-linkCheckCh:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			}
+
+			if err := CheckLinks(ctx); err != nil {
+				ctx.Log.Error("链接检查失败", zap.Error(err))
+			}
+			timer.Reset(interval)
+		}
+	}()
+}
+
+// TriggerLinkCheck 通知检查协程立即检查一次，非阻塞
+func TriggerLinkCheck() bool {
+	select {
+	case linkCheckCh <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// CheckLinks 检查当前加载的全部文档 url 并保存结果，检查正在进行时直接返回
+func CheckLinks(ctx *core.Context) error {
+
+	if !linkCheckMx.TryLock() {
+		return nil
+	}
+	defer linkCheckMx.Unlock()
+
+	if linkState.Load() == nil {
+		if err := openLinkStore(ctx); err != nil {
+			return err
+		}
+	}
+	store := linkState.Load().store
+
+	docs := current().allDocuments
+	if docs == nil {
+		return nil
+	}
+
+	keep := make(map[string]bool)
+	var urls []string
+	for _, d := range docs.GetDocumentsMap() {
+		u := strings.TrimSpace(d.Url)
+		if u == "" || keep[u] {
+			continue
+		}
+		keep[u] = true
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	start := time.Now()
+	checker := linkcheck.New(linkcheck.Options{
+		Timeout:     ctx.Conf.GetDuration("linkcheck.timeout"),
+		Concurrency: ctx.Conf.GetInt("linkcheck.concurrency"),
+		PerHost:     ctx.Conf.GetInt("linkcheck.per_host"),
+		Retries:     ctx.Conf.GetInt("linkcheck.retries"),
+	})
+
+	broken := 0
+	for _, r := range checker.CheckAll(context.Background(), urls) {
+		store.Record(r)
+		if !r.OK {
+			broken++
+		}
+	}
+	store.Prune(keep)
+
+	ctx.Log.Info("链接检查完成",
+		zap.Int("total", len(urls)),
+		zap.Int("broken", broken),
+		zap.Duration("duration", time.Since(start)))
+
+	refreshSnapshot(time.Now())
+
+	return store.Save()
+}
+
+// GetLinkStatuses url -> 链接状态（ok、broken），未启用检查时为空
+func GetLinkStatuses() map[string]string {
+	if state := linkState.Load(); state != nil {
+		return state.store.Statuses()
+	}
+	return map[string]string{}
+}
+
+// GetBrokenLinks 失效链接报告，按失效时间从早到晚排序
+func GetBrokenLinks() []BrokenLink {
+
+	list := []BrokenLink{}
+
+	state, docs := linkState.Load(), current().allDocuments
+	if state == nil || docs == nil {
+		return list
+	}
+	store := state.store
+
+	now := time.Now()
+	for _, d := range docs.GetDocumentsMap() {
+		h, ok := store.Get(d.Url)
+		if !ok || h.Status != linkcheck.StatusBroken {
+			continue
+		}
+		list = append(list, BrokenLink{
+			Slug:      d.Slug,
+			Name:      d.Name,
+			Url:       d.Url,
+			Source:    d.Source,
+			Hidden:    isLinkHidden(d, now),
+			Health:    h,
+			DownHours: int(h.DownFor(now).Hours()),
+		})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].Health.DownSince.Equal(list[j].Health.DownSince) {
+			return list[i].Health.DownSince.Before(list[j].Health.DownSince)
+		}
+		return list[i].Slug < list[j].Slug
+	})
+
+	return list
+}
+
+// isLinkHidden 文档链接是否已连续失效超过 linkcheck.hide_after_days
+func isLinkHidden(d doc.Document, now time.Time) bool {
+
+	state := linkState.Load()
+	if state == nil || state.hideAfter <= 0 {
+		return false
+	}
+
+	h, ok := state.store.Get(d.Url)
+	return ok && h.Status == linkcheck.StatusBroken && h.DownFor(now) >= state.hideAfter
+}
+
+func openLinkStore(ctx *core.Context) error {
+
+	path := ctx.Conf.GetString("linkcheck.store")
+	if path == "" {
+		path = "./data/linkcheck.json"
+	}
+
+	store, err := linkcheck.OpenStore(path, ctx.Conf.GetInt("linkcheck.history"))
+	if err != nil {
+		return err
+	}
+
+	linkState.Store(&linkHealth{
+		store:     store,
+		hideAfter: time.Duration(ctx.Conf.GetInt("linkcheck.hide_after_days")) * 24 * time.Hour,
+	})
+
+	return nil
+}
+
+// lastCheckTime 已保存结果中最近一次检查的时间
+func lastCheckTime() time.Time {
+	var last time.Time
+	for _, h := range linkState.Load().store.All() {
+		if h.Last.CheckedAt.After(last) {
+			last = h.Last.CheckedAt
+		}
+	}
+	return last
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/markdown"
+)
+
+// dataFilePath 校验并转换数据文件路径，file 为相对内容目录的路径，如 ai-tools/links.yaml
+func dataFilePath(ctx *core.Context, file string) (string, error) {
+
+	file = path.Clean("/" + strings.TrimSpace(file))[1:]
+	if file == "" || !markdown.IsDataFile(path.Base(file)) {
+		return "", errors.New("文件名必须为 links.yaml、links.yml、links.json 或 links.csv")
+	}
+
+	return filepath.Join(ctx.Conf.GetString("server.content_dir"), filepath.FromSlash(file)), nil
+}
+
+// GetLinkRecords 读取数据文件中的全部记录
+func GetLinkRecords(ctx *core.Context, file string) ([]markdown.LinkRecord, error) {
+
+	filePath, err := dataFilePath(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return []markdown.LinkRecord{}, nil
+	}
+
+	return markdown.ParseDataFile(filePath)
+}
+
+// SaveLinkRecord 新增或修改数据文件中的一条记录（按slug匹配，未指定slug时按url匹配），保存后重新加载数据
+func SaveLinkRecord(ctx *core.Context, file string, record markdown.LinkRecord) error {
+
+	if strings.TrimSpace(record.Name) == "" || strings.TrimSpace(record.Url) == "" {
+		return errors.New("name 和 url 不能为空")
+	}
+
+	if err := markdown.CheckRecordSlug(record.Slug); err != nil {
+		return err
+	}
+
+	filePath, err := dataFilePath(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	// 分类目录必须已存在
+	if _, err := os.Stat(filepath.Dir(filePath)); err != nil {
+		return errors.New("分类目录不存在")
+	}
+
+	records, err := GetLinkRecords(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	key := doc.RecordSlug(record)
+	replaced := false
+	for i, v := range records {
+		if doc.RecordSlug(v) == key || (record.Slug == "" && v.Url == record.Url) {
+			records[i] = record
+			replaced = true
+			break
+		}
+	}
+
+	if !replaced {
+		records = append(records, record)
+	}
+
+	if err := markdown.WriteDataFile(filePath, records); err != nil {
+		return err
+	}
+
+	return LoadAllData(ctx)
+}
+
+// DeleteLinkRecord 删除数据文件中的一条记录，slug 为记录的slug（可以是自动生成的）
+func DeleteLinkRecord(ctx *core.Context, file, slug string) error {
+
+	filePath, err := dataFilePath(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	records, err := GetLinkRecords(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	var kept []markdown.LinkRecord
+	for _, v := range records {
+		if doc.RecordSlug(v) != slug {
+			kept = append(kept, v)
+		}
+	}
+
+	if len(kept) == len(records) {
+		return os.ErrNotExist
+	}
+
+	if err := markdown.WriteDataFile(filePath, kept); err != nil {
+		return err
+	}
+
+	return LoadAllData(ctx)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/exchange"
+	"mdnav/internal/pkg/markdown"
+	"mdnav/internal/pkg/scraper"
+	"mdnav/internal/utils"
+)
+
+// NewLinkOptions 新建链接文档的参数，Name、Description 非空时覆盖从网页中提取的值
+type NewLinkOptions struct {
+	Url         string   `json:"url"`
+	Category    string   `json:"category"` // 分类 slug 或名称，不存在时新建
+	Slug        string   `json:"slug"`     // 文件名，为空时由名称或域名生成
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+	DryRun      bool     `json:"dry_run"` // 只返回将要写入的内容
+}
+
+// NewLinkResult 新建结果
+type NewLinkResult struct {
+	DryRun     bool          `json:"dry_run"`
+	Slug       string        `json:"slug"`
+	Categories []string      `json:"categories"` // 新建的分类
+	Meta       *scraper.Meta `json:"meta"`       // 网页元数据，抓取失败时为 nil
+	FetchError string        `json:"fetch_error,omitempty"`
+	Content    string        `json:"content"` // 生成的 md 文件内容
+}
+
+// NewLink 抓取网页的标题、描述、图片、关键词和 canonical 地址，在分类目录下生成链接文档。
+// 抓取失败时只要指定了名称仍会生成，url 已收录时返回错误
+func NewLink(ctx *core.Context, opts NewLinkOptions) (*NewLinkResult, error) {
+
+	opts.Url = strings.TrimSpace(opts.Url)
+	if urlHost(opts.Url) == "" {
+		return nil, fmt.Errorf("无效的网址 %q", opts.Url)
+	}
+	if strings.TrimSpace(opts.Category) == "" {
+		return nil, errors.New("分类不能为空")
+	}
+
+	existing := documentsByUrl()
+	if d, ok := existing[utils.NormalizeUrl(opts.Url)]; ok {
+		return nil, fmt.Errorf("url 已存在：%s", d.Slug)
+	}
+
+	result := &NewLinkResult{DryRun: opts.DryRun}
+
+	s := scraper.New(scraper.Options{
+		Timeout:   ctx.Conf.GetDuration("scraper.timeout"),
+		UserAgent: ctx.Conf.GetString("scraper.user_agent"),
+	})
+	meta, err := s.Fetch(context.Background(), opts.Url)
+	if err != nil {
+		if opts.Name == "" {
+			return nil, fmt.Errorf("抓取网页失败：%w", err)
+		}
+		result.FetchError = err.Error()
+		meta = &scraper.Meta{Url: opts.Url}
+	} else {
+		result.Meta = meta
+	}
+
+	md := markdown.Markdown{
+		Name:        firstNonEmpty(opts.Name, meta.Title, urlHost(opts.Url)),
+		Description: firstNonEmpty(opts.Description, meta.Description),
+		Keywords:    strings.Join(meta.Keywords, ","),
+		Image:       meta.Image,
+		Url:         linkUrl(opts.Url, meta.Canonical),
+		Tags:        opts.Tags,
+		Published:   true,
+		CreateTime:  time.Now().Truncate(time.Second),
+	}
+
+	// canonical 地址可能与已收录的文档相同
+	if d, ok := existing[utils.NormalizeUrl(md.Url)]; ok {
+		return nil, fmt.Errorf("url 已存在：%s", d.Slug)
+	}
+
+	base := utils.Slugify(opts.Slug)
+	if base == "" {
+		base = utils.Slugify(md.Name)
+	}
+	if base == "" {
+		base = utils.Slugify(strings.TrimPrefix(urlHost(md.Url), "www."))
+	}
+	if base == "" {
+		base = "link-" + utils.GenerateShortCode(md.Url)
+	}
+
+	dir := &exchange.Directory{}
+	im := newImporter(ctx, dir, ImportOptions{DryRun: opts.DryRun})
+
+	cateSlug, err := im.ensureCategory(newLinkCategory(dir, opts.Category))
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Slug, err = im.writeDocument(cateSlug, base, md); err != nil {
+		return nil, err
+	}
+	result.Categories = im.report.Categories
+
+	content, err := markdown.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+	result.Content = string(content)
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	return result, LoadAllData(ctx)
+}
+
+// newLinkCategory 按 slug 或名称匹配已有分类，都不匹配时按路径新建
+func newLinkCategory(dir *exchange.Directory, value string) string {
+
+	value = strings.Trim(strings.TrimSpace(value), "/")
+
+	if cates := current().allCategories; cates != nil {
+		all := cates.GetCategoriesMap()
+		if _, ok := all[value]; ok {
+			return value
+		}
+		for slug, c := range all {
+			if c.Name == value {
+				return slug
+			}
+		}
+	}
+
+	return dir.AddCategoryPath(value)
+}
+
+// linkUrl canonical 地址与输入地址为同一域名时使用 canonical，避免收录带跟踪参数的地址
+func linkUrl(input, canonical string) string {
+
+	if canonical == "" {
+		return input
+	}
+
+	a, err1 := url.Parse(input)
+	b, err2 := url.Parse(canonical)
+	if err1 != nil || err2 != nil {
+		return input
+	}
+	if strings.TrimPrefix(a.Hostname(), "www.") != strings.TrimPrefix(b.Hostname(), "www.") {
+		return input
+	}
+
+	return canonical
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"mdnav/internal/conf"
+	"mdnav/internal/core"
+	"mdnav/internal/lint"
+	"mdnav/internal/models/cate"
+	"mdnav/internal/models/page"
+	"mdnav/internal/pkg/zap"
+)
+
+// loadPages 加载独立页面并生成菜单，与分类或保留路由重名的页面无法访问，只记录错误
+func loadPages(ctx *core.Context, allCategories *cate.CategoriesMap) (*page.PagesMap, conf.Menus, error) {
+
+	loaded, err := page.New(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reserved := make(map[string]bool)
+	for _, v := range lint.ReservedSlugs {
+		reserved[v] = true
+	}
+
+	for _, p := range loaded.GetPagesSlice() {
+		top := strings.SplitN(p.Slug, "/", 2)[0]
+		if reserved[strings.ToLower(top)] {
+			ctx.Log.Error("页面与保留路由重名", zap.String("slug", p.Slug), zap.String("file", p.Source))
+		} else if allCategories.GetCategoriesBySlug(p.Slug) != nil {
+			ctx.Log.Error("页面与分类重名，将显示分类", zap.String("slug", p.Slug), zap.String("file", p.Source))
+		}
+	}
+
+	configured, err := conf.GetMenus()
+	if err != nil {
+		ctx.Log.Error("菜单配置错误", zap.Error(err))
+		configured = conf.Menus{}
+	}
+
+	return loaded, buildMenus(configured, loaded.GetPagesSlice()), nil
+}
+
+// buildMenus 把声明了 menu 的页面追加到对应菜单，再按权重排序，权重相同时保持配置顺序
+func buildMenus(configured conf.Menus, list []page.Page) conf.Menus {
+
+	result := make(conf.Menus, len(configured))
+	for name, items := range configured {
+		result[name] = append([]conf.MenuItem(nil), items...)
+	}
+
+	for _, p := range list {
+		if !p.IsVisible() {
+			continue
+		}
+		for _, name := range p.Menu {
+			item := conf.MenuItem{Name: p.Name, Page: p.Slug, Weight: p.Sort}
+			item.Normalize()
+			result[name] = append(result[name], item)
+		}
+	}
+
+	for _, items := range result {
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].Weight < items[j].Weight
+		})
+	}
+
+	return result
+}
+
+// GetPage 获取可见的独立页面
+func GetPage(slug string) *page.Page {
+
+	pages := current().pages
+	if pages == nil {
+		return nil
+	}
+
+	p := pages.GetPageBySlug(slug)
+	if p == nil || !p.IsVisible() {
+		return nil
+	}
+
+	return p
+}
+
+// GetMenus 获取站点菜单，键为菜单位置（header、footer）
+func GetMenus() conf.Menus {
+	return current().menus
+}
@@ -0,0 +1,197 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/cate"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/zap"
+)
+
+// 文档状态
+const (
+	StatusPublished = "published" // 已发布
+	StatusDraft     = "draft"     // 草稿 published: false
+	StatusHidden    = "hidden"    // 隐藏 is_show: false，或所属分类不可见
+	StatusPending   = "pending"   // 未到发布时间
+	StatusExpired   = "expired"   // 已过期
+	StatusBroken    = "broken"    // 链接连续失效超过 linkcheck.hide_after_days，已自动隐藏
+)
+
+const defaultPreviewTTL = 72 * time.Hour
+
+var (
+	previewSecret     []byte
+	previewSecretErr  error
+	previewSecretOnce sync.Once
+)
+
+// AdminDocument 管理后台的文档条目
+type AdminDocument struct {
+	Document   doc.Document `json:"document"`
+	Status     string       `json:"status"`
+	PreviewURL string       `json:"preview_url,omitempty"` // 非发布状态的文档附带预览链接
+}
+
+// DocumentStatus 获取文档当前的状态
+func DocumentStatus(d doc.Document, now time.Time) string {
+
+	switch {
+	case !d.Published:
+		return StatusDraft
+	case !d.IsShow:
+		return StatusHidden
+	case !d.PublishAt.IsZero() && now.Before(d.PublishAt):
+		return StatusPending
+	case !d.ExpireAt.IsZero() && !now.Before(d.ExpireAt):
+		return StatusExpired
+	}
+
+	if c := current().allCategories.GetCategoriesBySlug(d.CateSlug); c == nil || !c.IsVisible() {
+		return StatusHidden
+	}
+
+	if isLinkHidden(d, now) {
+		return StatusBroken
+	}
+
+	return StatusPublished
+}
+
+// GetAdminDocuments 获取全部文档及其状态，未发布的排在前面
+func GetAdminDocuments(ctx *core.Context) []AdminDocument {
+
+	now := time.Now()
+	ttl := previewTTL(ctx)
+
+	var list, published []AdminDocument
+	for _, d := range doc.SortDocuments(current().allDocuments.GetDocumentsSlice(), doc.SortByUpdateTime, doc.Descending) {
+
+		item := AdminDocument{Document: d, Status: DocumentStatus(d, now)}
+		if item.Status == StatusPublished {
+			published = append(published, item)
+			continue
+		}
+
+		if u, err := PreviewURL(ctx, d.Slug, now.Add(ttl)); err != nil {
+			ctx.Log.Error("生成预览链接失败", zap.String("slug", d.Slug), zap.Error(err))
+		} else {
+			item.PreviewURL = u
+		}
+		list = append(list, item)
+	}
+
+	return append(list, published...)
+}
+
+// PreviewURL 生成带签名的文档预览链接，过期时间之后失效。无法获取签名密钥时返回错误，不生成链接
+func PreviewURL(ctx *core.Context, slug string, expires time.Time) (string, error) {
+
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	sig, err := previewSignature(ctx, slug, exp)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("expires", exp)
+	query.Set("sig", sig)
+
+	return "/preview/" + slug + "?" + query.Encode(), nil
+}
+
+// VerifyPreview 校验预览链接的签名和有效期
+func VerifyPreview(ctx *core.Context, slug, expires, sig string) error {
+
+	exp, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return errors.New("预览链接无效")
+	}
+
+	if time.Now().Unix() > exp {
+		return errors.New("预览链接已过期")
+	}
+
+	expected, err := previewSignature(ctx, slug, expires)
+	if err != nil {
+		return err
+	}
+
+	if !hmac.Equal([]byte(sig), []byte(expected)) {
+		return errors.New("预览链接签名错误")
+	}
+
+	return nil
+}
+
+// GetPreviewDocument 获取任意状态的单个文档，用于预览
+func GetPreviewDocument(docSlug string) *CategoryDocument {
+
+	s := current()
+	document := s.allDocuments.GetDocumentBySlug(docSlug)
+	if document == nil {
+		return nil
+	}
+
+	category := s.allCategories.GetCategoriesBySlug(document.CateSlug)
+	if category == nil {
+		category = &cate.Category{Slug: document.CateSlug, Name: document.CateSlug}
+	}
+
+	ensureRendered(document)
+
+	return &CategoryDocument{
+		Document:  *document,
+		Category:  *category,
+		Backlinks: GetBacklinks(document.Slug),
+		Related:   GetRelatedDocuments(*document, relatedLimit),
+	}
+}
+
+func previewSignature(ctx *core.Context, slug, expires string) (string, error) {
+	key, err := previewKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(slug + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// previewKey 读取 server.preview_secret，未配置时使用进程启动时生成的随机密钥（重启后旧链接失效）。
+// 随机密钥生成失败时返回错误，此后不再签发或接受预览链接
+func previewKey(ctx *core.Context) ([]byte, error) {
+
+	if secret := ctx.Conf.GetString("server.preview_secret"); secret != "" {
+		return []byte(secret), nil
+	}
+
+	previewSecretOnce.Do(func() {
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			previewSecretErr = errors.New("生成预览密钥出错：" + err.Error())
+			return
+		}
+		previewSecret = key
+		ctx.Log.Warn("未配置 server.preview_secret，预览链接在重启后失效")
+	})
+
+	return previewSecret, previewSecretErr
+}
+
+func previewTTL(ctx *core.Context) time.Duration {
+	if ttl := ctx.Conf.GetDuration("server.preview_ttl"); ttl > 0 {
+		return ttl
+	}
+	return defaultPreviewTTL
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/bundle"
+	"mdnav/internal/pkg/linkpolicy"
+	"mdnav/internal/pkg/markdown"
+)
+
+// configureMarkdown 按 markdown 配置创建渲染引擎，按 link_policy 配置设置链接策略，未配置的选项使用默认值
+func configureMarkdown(ctx *core.Context) error {
+
+	policy := linkpolicy.DefaultPolicy()
+	if err := ctx.Conf.UnmarshalKey("link_policy", &policy); err != nil {
+		return err
+	}
+	linkpolicy.Configure(policy)
+
+	opts := markdown.DefaultOptions()
+	if err := ctx.Conf.UnmarshalKey("markdown", &opts); err != nil {
+		return err
+	}
+
+	return markdown.Configure(opts)
+}
+
+// renderDocuments 预先渲染可见文档的正文，返回新的 DocumentsMap
+func renderDocuments(visible *doc.DocumentsMap) *doc.DocumentsMap {
+
+	docs := visible.GetDocumentsSlice()
+	for i := range docs {
+		docs[i].Rendered = renderDocument(docs[i])
+	}
+
+	return visible.WithDocuments(docs)
+}
+
+// ensureRendered 文档还没有预先渲染时（如草稿预览）立即渲染
+func ensureRendered(d *doc.Document) {
+	if d.HTML == "" && d.Markdown != "" {
+		d.Rendered = renderDocument(*d)
+	}
+}
+
+// renderDocument 渲染文档正文，图片的相对路径指向文档所在目录中的资源
+func renderDocument(d doc.Document) markdown.Rendered {
+	return markdown.RenderWith(d.Markdown, func(ref string) string {
+		return bundle.URL(d.CateSlug, ref)
+	})
+}
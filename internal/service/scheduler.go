@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/zap"
+)
+
+// 数据重新加载后通知调度器重新计算下一个时间点
+var rescheduleCh = make(chan struct{}, 1)
+
+// ScheduledDocument 定时发布或定时过期的文档
+type ScheduledDocument struct {
+	Document doc.Document `json:"document"`
+	State    string       `json:"state"` // pending 未到发布时间, active 已发布待过期, expired 已过期
+}
+
+// StartScheduler 启动发布调度协程，在最近的 publish_at/expire_at 时间点自动刷新文档快照
+func StartScheduler(ctx *core.Context) {
+	go func() {
+		timer := time.NewTimer(time.Hour)
+		timer.Stop()
+
+		for {
+			next := time.Time{}
+			if docs := current().allDocuments; docs != nil {
+				next = docs.NextBoundary(time.Now())
+			}
+
+			if !next.IsZero() {
+				timer.Reset(time.Until(next))
+				ctx.Log.Info("下一次发布调度", zap.String("time", next.Format(time.RFC3339)))
+			}
+
+			select {
+			case <-timer.C:
+				refreshSnapshot(time.Now())
+				ctx.Log.Info("发布调度：文档快照已刷新")
+			case <-rescheduleCh:
+				if !timer.Stop() {
+					select {
+					case <-timer.C:
+					default:
+					}
+				}
+			}
+		}
+	}()
+}
+
+// reschedule 通知调度器重新计算，非阻塞
+func reschedule() {
+	select {
+	case rescheduleCh <- struct{}{}:
+	default:
+	}
+}
+
+// GetScheduledDocuments 获取设置了发布或过期时间、且尚未全部生效的文档，按最近时间点排序
+func GetScheduledDocuments() []ScheduledDocument {
+
+	now := time.Now()
+
+	var list []ScheduledDocument
+	for _, d := range current().allDocuments.GetDocumentsSlice() {
+
+		if d.PublishAt.IsZero() && d.ExpireAt.IsZero() {
+			continue
+		}
+
+		state := "active"
+		switch {
+		case !d.PublishAt.IsZero() && now.Before(d.PublishAt):
+			state = "pending"
+		case !d.ExpireAt.IsZero() && !now.Before(d.ExpireAt):
+			state = "expired"
+		}
+
+		list = append(list, ScheduledDocument{Document: d, State: state})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		ki, kj := scheduleKey(list[i].Document, now), scheduleKey(list[j].Document, now)
+		if ki.IsZero() != kj.IsZero() {
+			return !ki.IsZero()
+		}
+		return ki.Before(kj)
+	})
+
+	return list
+}
+
+// scheduleKey 文档下一个生效时间点，已全部生效时返回零值
+func scheduleKey(d doc.Document, now time.Time) time.Time {
+	if d.PublishAt.After(now) {
+		return d.PublishAt
+	}
+	if d.ExpireAt.After(now) {
+		return d.ExpireAt
+	}
+	return time.Time{}
+}
@@ -2,10 +2,11 @@ package service
 
 import (
 	"mdnav/internal/core"
-	"mdnav/internal/models"
 	"mdnav/internal/models/cate"
+	"mdnav/internal/models/collection"
 	"mdnav/internal/models/doc"
 	"sort"
+	"time"
 
 	"go.uber.org/zap"
 )
@@ -15,23 +16,30 @@ type CategoryDocuments struct {
 	DocumentList []doc.Document `json:"document_list"`
 }
 
+type CollectionDocuments struct {
+	Collection   collection.Collection `json:"collection"`
+	DocumentList []doc.Document        `json:"document_list"`
+}
+
 type CategoryDocument struct {
-	Category cate.Category `json:"category"`
-	Document doc.Document  `json:"document"`
+	Category  cate.Category  `json:"category"`
+	Document  doc.Document   `json:"document"`
+	Backlinks []doc.Document `json:"backlinks"` // 引用了该文档的文档
+	Related   []doc.Document `json:"related"`   // 相关文档
 }
 
-var categories *cate.CategoriesMap
-var documents *doc.DocumentsMap
-var cateDocsSlugMap *models.CateSlugDocsSlugMap
+// LoadAllData 加载所有数据，全部加载成功后替换当前快照，失败时继续使用原来的数据
+func LoadAllData(ctx *core.Context) error {
 
-// LoadAllData 加载所有数据
-func LoadAllData(ctx *core.Context) (err error) {
+	loadMx.Lock()
+	defer loadMx.Unlock()
 
-	categories = nil
-	documents = nil
-	cateDocsSlugMap = nil
+	if err := configureMarkdown(ctx); err != nil {
+		ctx.Log.Error("Markdown 配置错误", zap.Error(err))
+		return err
+	}
 
-	categories, err = cate.New(ctx)
+	allCategories, err := cate.New(ctx)
 	if err != nil {
 		ctx.Log.Error("分类数据加载失败", zap.Error(err))
 		return err
@@ -39,31 +47,61 @@ func LoadAllData(ctx *core.Context) (err error) {
 
 	ctx.Log.Info("分类数据加载完成")
 
-	documents, err = doc.New(ctx)
+	allDocuments, err := doc.New(ctx)
 	if err != nil {
 		ctx.Log.Error("文档数据加载失败", zap.Error(err))
 		return err
 	}
 	ctx.Log.Info("文档数据加载完成")
 
-	cateDocsSlugMap = models.GetCateDocsSlugMap(categories, documents)
+	s := &snapshot{
+		allCategories: allCategories,
+		allDocuments:  allDocuments,
+		links:         buildLinkGraph(ctx, allDocuments),
+	}
+	s = s.withVisible(time.Now())
 
 	ctx.Log.Info("分类文档映射数据加载完成")
 
+	// 合集介绍和独立页面在加载时渲染，其中的短代码和交叉链接同样读取新的数据
+	building.Store(s)
+	defer building.Store(nil)
+
+	collections, err := collection.New(ctx)
+	if err != nil {
+		ctx.Log.Error("合集数据加载失败", zap.Error(err))
+		return err
+	}
+	ctx.Log.Info("合集数据加载完成")
+
+	pages, menus, err := loadPages(ctx, allCategories)
+	if err != nil {
+		ctx.Log.Error("页面数据加载失败", zap.Error(err))
+		return err
+	}
+	ctx.Log.Info("页面数据加载完成")
+
+	next := *s
+	next.collections, next.pages, next.menus = collections, pages, menus
+	publish(&next)
+
+	reschedule()
+
 	return nil
 }
 
 // GetCategoriesDocuments 获取按分类文档归档好的数据
 func GetCategoriesDocuments(sortBy doc.SortBy, order doc.SortOrder) []CategoryDocuments {
 
+	s := current()
 	var categoryDocuments []CategoryDocuments
 
-	for _, category := range categories.GetCategoriesSlice() {
+	for _, category := range s.categories.GetCategoriesSlice() {
 
-		docsSlug := cateDocsSlugMap.GetCateDocsSliceBySlug(category.Slug)
+		docsSlug := s.cateDocsSlugMap.GetCateDocsSliceBySlug(category.Slug)
 		var docs []doc.Document
 		for _, docSlug := range docsSlug {
-			d := documents.GetDocumentBySlug(docSlug)
+			d := s.documents.GetDocumentBySlug(docSlug)
 			if d == nil {
 				continue
 			}
@@ -80,9 +118,10 @@ func GetCategoriesDocuments(sortBy doc.SortBy, order doc.SortOrder) []CategoryDo
 // GetCategoryDocumentsByCateSlug 根据分类slug获取按分类文档归档好的数据
 func GetCategoryDocumentsByCateSlug(cateSlug string, sortBy doc.SortBy, order doc.SortOrder) *CategoryDocuments {
 
+	s := current()
 	cateDoc := &CategoryDocuments{}
 
-	category := categories.GetCategoriesBySlug(cateSlug)
+	category := s.categories.GetCategoriesBySlug(cateSlug)
 
 	if category == nil {
 		return nil
@@ -90,8 +129,8 @@ func GetCategoryDocumentsByCateSlug(cateSlug string, sortBy doc.SortBy, order do
 
 	cateDoc.Category = *category
 	var docs []doc.Document
-	for _, docSlug := range cateDocsSlugMap.GetCateDocsSliceBySlug(cateSlug) {
-		d := documents.GetDocumentBySlug(docSlug)
+	for _, docSlug := range s.cateDocsSlugMap.GetCateDocsSliceBySlug(cateSlug) {
+		d := s.documents.GetDocumentBySlug(docSlug)
 		if d == nil {
 			continue
 		}
@@ -102,18 +141,61 @@ func GetCategoryDocumentsByCateSlug(cateSlug string, sortBy doc.SortBy, order do
 	return cateDoc
 }
 
+// GetCategoryDocuments 获取分类下的文档，按分类 _index.md 中声明的默认排序
+func GetCategoryDocuments(cateSlug string) *CategoryDocuments {
+
+	s := current()
+	category := s.categories.GetCategoriesBySlug(cateSlug)
+	if category == nil {
+		return nil
+	}
+
+	sortBy, order := CategorySort(*category)
+	return GetCategoryDocumentsByCateSlug(cateSlug, sortBy, order)
+}
+
+// CategorySort 分类的默认排序，未声明或无法识别时按更新时间升序；
+// 只声明 sort_by: sort 时按权重降序，与权重越大越靠前的约定一致
+func CategorySort(c cate.Category) (doc.SortBy, doc.SortOrder) {
+
+	sortBy, err := doc.ParseSortBy(c.SortBy)
+	if err != nil {
+		return doc.SortByUpdateTime, doc.Ascending
+	}
+
+	order, err := doc.ParseSortOrder(c.SortOrder)
+	if err != nil {
+		order = doc.Ascending
+		if sortBy == doc.SortBySort {
+			order = doc.Descending
+		}
+	}
+
+	return sortBy, order
+}
+
 // GetDocument 获取单个文档
 func GetDocument(docSlug string) *CategoryDocument {
 
+	s := current()
 	categoryDocument := &CategoryDocument{}
 
-	document := documents.GetDocumentBySlug(docSlug)
+	document := s.documents.GetDocumentBySlug(docSlug)
 	if document == nil {
 		return nil
 	}
 
+	category := s.categories.GetCategoriesBySlug(document.CateSlug)
+	if category == nil {
+		return nil
+	}
+
+	ensureRendered(document)
+
 	categoryDocument.Document = *document
-	categoryDocument.Category = *categories.GetCategoriesBySlug(document.CateSlug)
+	categoryDocument.Category = *category
+	categoryDocument.Backlinks = GetBacklinks(document.Slug)
+	categoryDocument.Related = GetRelatedDocuments(*document, relatedLimit)
 
 	return categoryDocument
 }
@@ -121,7 +203,8 @@ func GetDocument(docSlug string) *CategoryDocument {
 // GetPageDocuments 获取分页后的文档数据
 func GetPageDocuments(page, pageSize int, sortBy doc.SortBy, order doc.SortOrder) doc.PageResult {
 
-	allDocuments := documents.GetDocumentsSlice()
+	s := current()
+	allDocuments := s.documents.GetDocumentsSlice()
 
 	orderAllDocuments := doc.SortDocuments(allDocuments, sortBy, order)
 
@@ -130,15 +213,18 @@ func GetPageDocuments(page, pageSize int, sortBy doc.SortBy, order doc.SortOrder
 
 // GetAllCategoryMap 获取所有分类map数据 map[cateSlug]Category
 func GetAllCategoryMap() map[string]cate.Category {
-	return categories.GetCategoriesMap()
+
+	s := current()
+	return s.categories.GetCategoriesMap()
 }
 
 // GetTagDocuments 根据标签名获取文档数据
 func GetTagDocuments(tagName string, sortBy doc.SortBy, order doc.SortOrder) []CategoryDocuments {
 
+	s := current()
 	var docs []doc.Document
-	for _, docSlug := range documents.GetDocumentsSlugByTag(tagName) {
-		d := documents.GetDocumentBySlug(docSlug)
+	for _, docSlug := range s.documents.GetDocumentsSlugByTag(tagName) {
+		d := s.documents.GetDocumentBySlug(docSlug)
 		if d != nil {
 			docs = append(docs, *d)
 		}
@@ -156,7 +242,7 @@ func GetTagDocuments(tagName string, sortBy doc.SortBy, order doc.SortOrder) []C
 
 	for cateSlug, docsVal := range cateSlugDocsMap {
 
-		category := categories.GetCategoriesBySlug(cateSlug)
+		category := s.categories.GetCategoriesBySlug(cateSlug)
 		if category != nil {
 			categoryDocuments := CategoryDocuments{Category: *category, DocumentList: doc.SortDocuments(docsVal, sortBy, order)}
 			tagDocuments = append(tagDocuments, categoryDocuments)
@@ -167,18 +253,22 @@ func GetTagDocuments(tagName string, sortBy doc.SortBy, order doc.SortOrder) []C
 	return tagDocuments
 }
 
-// GetSiteInfo 获取网站信息
+// GetSiteInfo 获取网站信息，未配置的项使用主题的默认参数
 func GetSiteInfo(ctx *core.Context) map[string]any {
+	if state := activeTheme.Load(); state != nil && state.params != nil {
+		return state.params
+	}
 	return ctx.Conf.GetStringMap("site")
 }
 
 // GetAllCategories 获取所有分类数据
 func GetAllCategories() []cate.Category {
 
+	s := current()
 	var cates []cate.Category
 
-	for cateSlug, docsSlug := range cateDocsSlugMap.GetCateDocsSlugMap() {
-		c := *categories.GetCategoriesBySlug(cateSlug)
+	for cateSlug, docsSlug := range s.cateDocsSlugMap.GetCateDocsSlugMap() {
+		c := *s.categories.GetCategoriesBySlug(cateSlug)
 		c.DocumentCount = len(docsSlug)
 		cates = append(cates, c)
 	}
@@ -187,15 +277,18 @@ func GetAllCategories() []cate.Category {
 }
 
 func GetCategoryBySlug(slug string) cate.Category {
-	cates := categories.GetCategoriesBySlug(slug)
+
+	s := current()
+	cates := s.categories.GetCategoriesBySlug(slug)
 	return *cates
 }
 
 // GetAllTags 获取所有tag数据
 func GetAllTags() []string {
 
+	s := current()
 	var tags []string
-	for k := range documents.GetTags() {
+	for k := range s.documents.GetTags() {
 		tags = append(tags, k)
 	}
 	sort.Strings(tags)
@@ -206,3 +299,90 @@ func GetTopCategories() []cate.Category {
 
 	return nil
 }
+
+// QueryDocuments 根据过滤表达式查询文档，未指定排序时按排序权重降序
+func QueryDocuments(q *doc.Query) []doc.Document {
+	return queryDocuments(current(), q)
+}
+
+// queryDocuments 在指定快照的可见文档中查询
+func queryDocuments(s *snapshot, q *doc.Query) []doc.Document {
+
+	docs := s.documents.GetDocumentsSlice()
+	if q == nil {
+		return doc.SortDocuments(docs, doc.SortBySort, doc.Descending)
+	}
+
+	if q.SortBy == "" {
+		docs = doc.SortDocuments(docs, doc.SortBySort, doc.Descending)
+	}
+
+	return q.Apply(docs)
+}
+
+// FilterCategoriesDocuments 使用过滤表达式过滤按分类归档的数据，去掉过滤后为空的分类
+func FilterCategoriesDocuments(list []CategoryDocuments, q *doc.Query) []CategoryDocuments {
+
+	if q.IsEmpty() {
+		return list
+	}
+
+	result := []CategoryDocuments{}
+	for _, v := range list {
+		docs := q.Apply(v.DocumentList)
+		if len(docs) == 0 {
+			continue
+		}
+		result = append(result, CategoryDocuments{Category: v.Category, DocumentList: docs})
+	}
+
+	return result
+}
+
+// GetAllCollections 获取所有已发布的合集
+func GetAllCollections() []collection.Collection {
+
+	s := current()
+	var list []collection.Collection
+	for _, c := range s.collections.GetCollectionsSlice() {
+		if c.Published {
+			list = append(list, c)
+		}
+	}
+
+	return list
+}
+
+// GetCollection 根据slug获取合集及其文档，先按 items 顺序列出，再追加过滤表达式的结果
+func GetCollection(slug string) *CollectionDocuments {
+
+	s := current()
+	c := s.collections.GetCollectionBySlug(slug)
+	if c == nil || !c.Published {
+		return nil
+	}
+
+	result := &CollectionDocuments{Collection: *c}
+	seen := make(map[string]bool)
+
+	for _, docSlug := range c.Items {
+		d := s.documents.GetDocumentBySlug(docSlug)
+		if d == nil || seen[docSlug] {
+			continue
+		}
+		seen[docSlug] = true
+		result.DocumentList = append(result.DocumentList, *d)
+	}
+
+	if q := c.GetQuery(); q != nil {
+		for _, d := range QueryDocuments(q) {
+			if seen[d.Slug] {
+				continue
+			}
+			seen[d.Slug] = true
+			result.DocumentList = append(result.DocumentList, d)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"html/template"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/shortcode"
+)
+
+// 依赖站点数据的内置短代码
+func init() {
+	shortcode.Register("link", linkShortcode)
+	shortcode.Register("links", linksShortcode)
+	shortcode.Register("tags", tagsShortcode)
+}
+
+// linkShortcode 链接卡片：{{< link development-resources/github >}}，参数为文档 slug
+func linkShortcode(c *shortcode.Call) (template.HTML, error) {
+
+	slug := strings.Trim(c.Value("slug", 0), "/")
+	if slug == "" {
+		return "", errors.New("缺少文档 slug")
+	}
+
+	s := rendering()
+	if s.documents == nil {
+		return "", errors.New("文档尚未加载")
+	}
+	d := s.documents.GetDocumentBySlug(slug)
+	if d == nil {
+		return "", errors.New("文档 " + slug + " 不存在或未发布")
+	}
+
+	// 链接被拦截时只显示文档信息
+	tag := "span"
+	var b strings.Builder
+	if href, attrs := shortcode.OutboundLink(d.Url); href != "" {
+		tag = "a"
+		b.WriteString(`<a class="shortcode-link" href="` + html.EscapeString(href) + `"` + attrs + `>`)
+	} else {
+		b.WriteString(`<span class="shortcode-link">`)
+	}
+	if icon := shortcode.SafeURL(IconURL(*d)); icon != "" {
+		b.WriteString(`<img src="` + html.EscapeString(icon) + `" alt="" loading="lazy">`)
+	}
+	b.WriteString(`<strong>` + html.EscapeString(d.Name) + `</strong>`)
+	if d.Description != "" {
+		b.WriteString(`<span>` + html.EscapeString(d.Description) + `</span>`)
+	}
+	b.WriteString(`</` + tag + `>`)
+
+	return template.HTML(b.String()), nil
+}
+
+// linksShortcode 文档列表：{{< links tag="AI" category="ai-tools" sort="-sort" limit=5 >}}，
+// 也可以用 q 直接写过滤表达式，未指定排序时按排序权重降序
+func linksShortcode(c *shortcode.Call) (template.HTML, error) {
+
+	var parts []string
+	if expr := c.Get("q"); expr != "" {
+		parts = append(parts, expr)
+	}
+	for _, field := range []string{"tag", "category", "sort", "limit"} {
+		if v := c.Get(field); v != "" {
+			parts = append(parts, field+":"+strconv.Quote(v))
+		}
+	}
+	if len(parts) == 0 {
+		return "", errors.New("至少需要 tag、category 或 q 中的一个")
+	}
+
+	q, err := doc.ParseQuery(strings.Join(parts, " "))
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="shortcode-links">`)
+	for _, d := range queryDocuments(rendering(), q) {
+		if href, attrs := shortcode.OutboundLink(d.Url); href != "" {
+			b.WriteString(`<li><a href="` + html.EscapeString(href) + `"` + attrs + `>` + html.EscapeString(d.Name) + `</a>`)
+		} else {
+			b.WriteString(`<li>` + html.EscapeString(d.Name))
+		}
+		if d.Description != "" {
+			b.WriteString(` <span>` + html.EscapeString(d.Description) + `</span>`)
+		}
+		b.WriteString(`</li>`)
+	}
+	b.WriteString(`</ul>`)
+
+	return template.HTML(b.String()), nil
+}
+
+// tagsShortcode 标签云：{{< tags limit=30 >}}，按文档数量降序，带数量
+func tagsShortcode(c *shortcode.Call) (template.HTML, error) {
+
+	s := rendering()
+	if s.documents == nil {
+		return "", errors.New("文档尚未加载")
+	}
+
+	type tagCount struct {
+		name  string
+		count int
+	}
+
+	var tags []tagCount
+	for name, slugs := range s.documents.GetTags() {
+		tags = append(tags, tagCount{name, len(slugs)})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].count == tags[j].count {
+			return tags[i].name < tags[j].name
+		}
+		return tags[i].count > tags[j].count
+	})
+
+	if limit, err := strconv.Atoi(c.Value("limit", 0)); err == nil && limit > 0 && limit < len(tags) {
+		tags = tags[:limit]
+	}
+
+	var b strings.Builder
+	b.WriteString(`<nav class="shortcode-tags tags">`)
+	for _, t := range tags {
+		b.WriteString(`<a href="/tag/` + html.EscapeString(url.PathEscape(t.name)) + `">` + html.EscapeString(t.name) +
+			`<small>` + strconv.Itoa(t.count) + `</small></a>`)
+	}
+	b.WriteString(`</nav>`)
+
+	return template.HTML(b.String()), nil
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mdnav/internal/conf"
+	"mdnav/internal/models"
+	"mdnav/internal/models/cate"
+	"mdnav/internal/models/collection"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/models/page"
+)
+
+// snapshot 一次加载得到的全部数据。加载和刷新时先在局部变量中生成新的快照，完成后整体替换，
+// 访客请求通过 current() 读取，不会读到加载到一半的数据
+type snapshot struct {
+	allCategories   *cate.CategoriesMap // 加载的全部分类，包括草稿
+	categories      *cate.CategoriesMap // 当前可见的分类
+	allDocuments    *doc.DocumentsMap   // 加载的全部文档，包括未到发布时间和已过期的
+	documents       *doc.DocumentsMap   // 当前可见的文档
+	cateDocsSlugMap *models.CateSlugDocsSlugMap
+	collections     *collection.CollectionsMap
+	pages           *page.PagesMap // pages_dir 下的独立页面
+	menus           conf.Menus     // 配置的菜单加上声明了 menu 的页面
+	links           *linkGraph     // 交叉链接索引
+	icons           iconSources    // 可见文档用到的图标地址
+}
+
+var (
+	active   atomic.Pointer[snapshot] // 当前快照，首次加载完成前为 nil
+	building atomic.Pointer[snapshot] // 正在生成的快照，预先渲染期间短代码和交叉链接从这里读取
+	loadMx   sync.Mutex               // 同一时间只有一个加载或刷新在生成快照，避免刷新覆盖更新的加载结果
+)
+
+// current 当前快照，首次加载完成前为空快照
+func current() *snapshot {
+	if s := active.Load(); s != nil {
+		return s
+	}
+	return &snapshot{links: &linkGraph{}}
+}
+
+// rendering 渲染正文时短代码和交叉链接读取的快照：生成新快照期间为新快照，其余时间为当前快照
+func rendering() *snapshot {
+	if s := building.Load(); s != nil {
+		return s
+	}
+	return current()
+}
+
+// loaded 是否已完成首次加载
+func (s *snapshot) loaded() bool {
+	return s.allDocuments != nil && s.allCategories != nil
+}
+
+// withVisible 按指定时间重新计算可见的分类、文档及分类映射，返回新的快照，s 本身不修改。
+// 草稿、隐藏、不在发布窗口内、所属分类不可见以及链接长期失效的文档都会被排除
+func (s *snapshot) withVisible(now time.Time) *snapshot {
+
+	next := *s
+
+	next.categories = s.allCategories.Filter(func(c cate.Category) bool {
+		return c.IsVisible()
+	})
+
+	next.documents = s.allDocuments.Filter(func(d doc.Document) bool {
+		return d.IsVisible(now) && next.categories.GetCategoriesBySlug(d.CateSlug) != nil && !isLinkHidden(d, now)
+	})
+
+	next.cateDocsSlugMap = models.GetCateDocsSlugMap(next.categories, next.documents)
+
+	return &next
+}
+
+// publish 预先渲染可见文档的正文，再整体替换当前快照
+func publish(s *snapshot) {
+
+	building.Store(s)
+	defer building.Store(nil)
+
+	next := *s
+	next.documents = renderDocuments(s.documents)
+	next.icons = buildIconSources(s.documents)
+	active.Store(&next)
+}
+
+// refreshSnapshot 按指定时间重新计算可见部分并替换当前快照，供定时发布和链接检查等后台协程调用
+func refreshSnapshot(now time.Time) {
+
+	loadMx.Lock()
+	defer loadMx.Unlock()
+
+	s := current()
+	if !s.loaded() {
+		return
+	}
+
+	publish(s.withVisible(now))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"sync/atomic"
+
+	"mdnav/internal/core"
+	"mdnav/internal/pkg/theme"
+)
+
+// themeState 当前主题及合并主题默认参数后的 site 配置，重新加载时整体替换
+type themeState struct {
+	theme  *theme.Theme
+	params map[string]any
+}
+
+var activeTheme atomic.Pointer[themeState] // 当前主题，加载前为 nil
+
+// LoadTheme 按 theme.name 加载主题，未配置时使用内置的默认主题。主题参数作为 site 配置的默认值，
+// site 配置不符合主题声明的配置项时返回错误
+func LoadTheme(ctx *core.Context) error {
+
+	name := ctx.Conf.GetString("theme.name")
+	if name == "" {
+		name = theme.DefaultName
+	}
+
+	t, err := theme.Load(ctx.Conf.GetString("theme.dir"), name)
+	if err != nil {
+		return err
+	}
+
+	params, err := t.SiteParams(ctx.Conf.GetStringMap("site"))
+	if err != nil {
+		return err
+	}
+
+	activeTheme.Store(&themeState{theme: t, params: params})
+	return nil
+}
+
+// GetTheme 当前主题
+func GetTheme() *theme.Theme {
+	if state := activeTheme.Load(); state != nil {
+		return state.theme
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"sort"
+
+	"mdnav/internal/core"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/wikilink"
+	"mdnav/internal/pkg/zap"
+)
+
+// relatedLimit 文档页"相关推荐"的数量
+const relatedLimit = 6
+
+// BrokenWikiLink 无法解析的交叉链接
+type BrokenWikiLink struct {
+	Slug   string `json:"slug"`   // 引用所在的文档
+	Source string `json:"source"` // 引用所在的文件
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// linkGraph 交叉链接索引，随快照一起替换
+type linkGraph struct {
+	index     *wikilink.Index     // 全部文档的 slug 和名称索引
+	outlinks  map[string][]string // 文档 -> 引用的文档
+	backlinks map[string][]string // 文档 -> 引用它的文档
+	broken    []BrokenWikiLink    // 无法解析的引用
+}
+
+func init() {
+	wikilink.SetResolver(resolveWikiLink)
+}
+
+// buildLinkGraph 解析全部文档正文中的交叉链接，生成引用和被引用索引，无法解析的引用记录警告
+func buildLinkGraph(ctx *core.Context, all *doc.DocumentsMap) *linkGraph {
+
+	docs := all.GetDocumentsSlice()
+	sort.Slice(docs, func(i, j int) bool {
+		return docs[i].Slug < docs[j].Slug
+	})
+
+	index := wikilink.NewIndex()
+	for _, d := range docs {
+		index.Add(d.Slug, d.Name)
+	}
+
+	out := make(map[string][]string)
+	back := make(map[string][]string)
+	var broken []BrokenWikiLink
+
+	for _, d := range docs {
+		seen := make(map[string]bool)
+		for _, ref := range wikilink.Extract(d.Markdown) {
+			target, err := index.Resolve(ref.Target)
+			if err != nil {
+				broken = append(broken, BrokenWikiLink{Slug: d.Slug, Source: d.Source, Target: ref.Target, Reason: err.Error()})
+				ctx.Log.Warn("无效的文档引用", zap.String("file", d.Source), zap.String("target", ref.Target), zap.Error(err))
+				continue
+			}
+			if target == d.Slug || seen[target] {
+				continue
+			}
+			seen[target] = true
+			out[d.Slug] = append(out[d.Slug], target)
+			back[target] = append(back[target], d.Slug)
+		}
+	}
+
+	return &linkGraph{index: index, outlinks: out, backlinks: back, broken: broken}
+}
+
+// resolveWikiLink 渲染时解析交叉链接，只链接到当前可见的文档
+func resolveWikiLink(target string) (href, title string, ok bool) {
+
+	s := rendering()
+	index, visible := s.links.index, s.documents
+	if index == nil || visible == nil {
+		return "", "", false
+	}
+
+	slug, err := index.Resolve(target)
+	if err != nil {
+		return "", "", false
+	}
+
+	d := visible.GetDocumentBySlug(slug)
+	if d == nil {
+		return "", "", false
+	}
+
+	return "/article/" + d.Slug, d.Name, true
+}
+
+// GetBacklinks 引用了该文档的可见文档
+func GetBacklinks(slug string) []doc.Document {
+	s := current()
+	return visibleDocuments(s, s.links.backlinks[slug])
+}
+
+// GetBrokenWikiLinks 无法解析的交叉链接
+func GetBrokenWikiLinks() []BrokenWikiLink {
+	if broken := current().links.broken; broken != nil {
+		return broken
+	}
+	return []BrokenWikiLink{}
+}
+
+// GetRelatedDocuments 相关文档：互相引用的文档权重最高，其次是相同标签数量，同一分类略微加分
+func GetRelatedDocuments(d doc.Document, limit int) []doc.Document {
+
+	s := current()
+	if s.documents == nil {
+		return nil
+	}
+
+	linked := make(map[string]bool)
+	for _, slug := range s.links.outlinks[d.Slug] {
+		linked[slug] = true
+	}
+	for _, slug := range s.links.backlinks[d.Slug] {
+		linked[slug] = true
+	}
+
+	tags := make(map[string]bool)
+	for _, t := range d.Tags {
+		tags[t] = true
+	}
+
+	type scored struct {
+		doc   doc.Document
+		score int
+	}
+
+	var list []scored
+	for _, v := range s.documents.GetDocumentsSlice() {
+		if v.Slug == d.Slug {
+			continue
+		}
+		score := 0
+		if linked[v.Slug] {
+			score += 3
+		}
+		for _, t := range v.Tags {
+			if tags[t] {
+				score++
+			}
+		}
+		if score == 0 {
+			continue
+		}
+		if v.CateSlug == d.CateSlug {
+			score++
+		}
+		list = append(list, scored{v, score})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].score != list[j].score {
+			return list[i].score > list[j].score
+		}
+		if list[i].doc.Sort != list[j].doc.Sort {
+			return list[i].doc.Sort > list[j].doc.Sort
+		}
+		return list[i].doc.Slug < list[j].doc.Slug
+	})
+
+	if len(list) > limit {
+		list = list[:limit]
+	}
+
+	related := make([]doc.Document, 0, len(list))
+	for _, v := range list {
+		related = append(related, v.doc)
+	}
+
+	return related
+}
+
+// visibleDocuments 按 slug 获取当前可见的文档，跳过不可见的
+func visibleDocuments(s *snapshot, slugs []string) []doc.Document {
+
+	var list []doc.Document
+	if s.documents == nil {
+		return list
+	}
+
+	for _, slug := range slugs {
+		if d := s.documents.GetDocumentBySlug(slug); d != nil {
+			list = append(list, *d)
+		}
+	}
+
+	return list
+}
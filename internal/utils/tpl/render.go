@@ -2,15 +2,26 @@ package tpl
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"html/template"
+	"io/fs"
 	"maps"
-	"path"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
+	"mdnav/internal/conf"
+	"mdnav/internal/models/doc"
+	"mdnav/internal/pkg/linkpolicy"
 	"mdnav/internal/pkg/markdown"
-	"mdnav/internal/utils"
+	"mdnav/internal/pkg/shortcode"
+	"mdnav/internal/pkg/theme"
+	"mdnav/internal/service"
 )
 
+// funcMaps 模板函数，只在包初始化时注册，之后编译模板时只读
 var funcMaps = template.FuncMap{
 	"md2html": func(md string) template.HTML {
 		return markdown.ConvertMarkdownToHTML([]byte(md))
@@ -18,27 +29,357 @@ var funcMaps = template.FuncMap{
 	"timeFormat": func(t time.Time) string {
 		return t.Format("2006-01-02 15:04:05")
 	},
+	// isNew 文档是否在 badge.new_window 时间内添加
+	"isNew": func(d doc.Document) bool {
+		return !d.CreateTime.IsZero() && time.Since(d.CreateTime) <= badgeWindow()
+	},
+	// isUpdated 文档是否在 badge.new_window 时间内更新过（新添加的不算）
+	"isUpdated": func(d doc.Document) bool {
+		window := badgeWindow()
+		if time.Since(d.UpdateTime) > window {
+			return false
+		}
+		return d.CreateTime.IsZero() || time.Since(d.CreateTime) > window
+	},
+	// iconUrl 文档图标地址，启用 icons 时为本站缓存的网站图标
+	"iconUrl": service.IconURL,
+	"add": func(a, b int) int {
+		return a + b
+	},
+	// dict 构造键值对，用于向子模板传递多个参数
+	"dict": func(kv ...any) (map[string]any, error) {
+		if len(kv)%2 != 0 {
+			return nil, errors.New("dict 参数必须成对出现")
+		}
+		m := make(map[string]any, len(kv)/2)
+		for i := 0; i < len(kv); i += 2 {
+			key, ok := kv[i].(string)
+			if !ok {
+				return nil, errors.New("dict 的键必须为字符串")
+			}
+			m[key] = kv[i+1]
+		}
+		return m, nil
+	},
+	// query 在模板中执行过滤表达式，例如 {{range query "tag:image sort:-sort limit:6"}}
+	"query": func(expr string) ([]doc.Document, error) {
+		q, err := doc.ParseQuery(expr)
+		if err != nil {
+			return nil, err
+		}
+		return service.QueryDocuments(q), nil
+	},
+}
+
+// badgeWindow "新"、"更新"标记的时间窗口，默认7天
+func badgeWindow() time.Duration {
+	if c := conf.Get(); c != nil {
+		if window := c.GetDuration("badge.new_window"); window > 0 {
+			return window
+		}
+	}
+	return 7 * 24 * time.Hour
+}
+
+// Set 编译好的模板。layouts/ 和 partials/ 下的模板为公共部分，其余顶层的 html 为页面，每个页面与公共部分组成一个独立的模板，
+// 模板以相对路径命名（如 partials/card.html），出错时的位置即为文件和行号
+type Set struct {
+	fsys  fs.FS
+	pages map[string]*template.Template
+	files map[string]time.Time // 参与编译的文件及修改时间，调试模式下用于判断是否需要重新编译
 }
 
-func Render(tplDir, tplName string, data any, funcMap ...template.FuncMap) ([]byte, error) {
+// 公共模板所在的子目录
+var sharedDirs = []string{"layouts", "partials"}
 
-	tplFile := path.Join(tplDir, tplName)
+// 自定义短代码模板所在的子目录，shortcodes/<name>.html 对应 {{< name >}}
+const shortcodeDir = "shortcodes"
 
-	if !utils.PathExist(tplFile) {
-		tplFile = path.Join(tplDir, "default.html")
+func init() {
+	shortcode.SetSource(templateShortcode)
+	// outUrl、linkAttrs 按 link_policy 处理文档链接
+	maps.Copy(funcMaps, linkpolicy.FuncMap)
+}
+
+// templateShortcode 查找模板目录中的短代码，模板的数据为 *shortcode.Call
+func templateShortcode(name string) (shortcode.Func, bool) {
+
+	set, err := load()
+	if err != nil {
+		return nil, false
 	}
 
-	if len(funcMap) > 0 {
-		maps.Copy(funcMaps, funcMap[0])
+	t, ok := set.pages[shortcodeDir+"/"+name+".html"]
+	if !ok {
+		return nil, false
 	}
 
-	tpl := template.New(tplName).Funcs(funcMaps)
+	return func(c *shortcode.Call) (template.HTML, error) {
+		buf := bytes.Buffer{}
+		if err := t.Execute(&buf, c); err != nil {
+			return "", err
+		}
+		return template.HTML(buf.String()), nil
+	}, true
+}
+
+var (
+	setMx   sync.RWMutex
+	current *Set
+	assets  fs.FS
+	watch   bool // 调试模式，模板文件变化后自动重新编译
+)
+
+// Init 组合模板查找链并编译，模板有错误时返回全部错误；debug 为 true 时模板文件变化后自动重新编译
+func Init(debug bool) error {
+
+	setMx.Lock()
+	watch = debug
+	setMx.Unlock()
+
+	return Reload()
+}
 
-	tpl, err := tpl.ParseFiles(tplFile)
+// Reload 重新组合模板查找链并编译，配置或主题变化后调用，失败时继续使用原来的模板
+func Reload() error {
+
+	templates, static := sources()
+
+	set, err := Compile(templates)
+	if err != nil {
+		return err
+	}
+
+	setMx.Lock()
+	current, assets = set, static
+	setMx.Unlock()
+
+	return nil
+}
+
+// sources 模板和静态资源的查找链：template.dir、template.static_dir 中的文件优先，其次是当前主题，
+// 最后是内置的默认主题，因此主题和站点目录都只需包含要修改的文件
+func sources() (templates, static fs.FS) {
+
+	var tl, al []fs.FS
+
+	if c := conf.Get(); c != nil {
+		if dir := c.GetString("template.dir"); dir != "" {
+			tl = append(tl, os.DirFS(dir))
+		}
+		if dir := c.GetString("template.static_dir"); dir != "" {
+			al = append(al, os.DirFS(dir))
+		}
+	}
+
+	if t := service.GetTheme(); t != nil {
+		tl = append(tl, t.Templates())
+		al = append(al, t.Assets())
+	}
+
+	if t, err := theme.Builtin(theme.DefaultName); err == nil {
+		tl = append(tl, t.Templates())
+		al = append(al, t.Assets())
+	}
+
+	return theme.Overlay(tl...), theme.Overlay(al...)
+}
+
+// Assets 静态资源，重新加载主题后自动使用新的查找链
+var Assets fs.FS = assetsFS{}
+
+type assetsFS struct{}
+
+func (assetsFS) Open(name string) (fs.File, error) {
+
+	setMx.RLock()
+	static := assets
+	setMx.RUnlock()
+
+	if static == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return static.Open(name)
+}
+
+// Compile 编译模板文件系统
+func Compile(fsys fs.FS) (*Set, error) {
+
+	shared, pages, err := templateFiles(fsys)
 	if err != nil {
 		return nil, err
 	}
 
+	set := &Set{
+		fsys:  fsys,
+		pages: make(map[string]*template.Template),
+		files: make(map[string]time.Time),
+	}
+
+	var errs []error
+
+	common := template.New("").Funcs(funcMaps)
+	for _, name := range shared {
+		if err := set.parse(common, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	for _, name := range pages {
+		t, err := common.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if err := set.parse(t, name); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		set.pages[name] = t.Lookup(name)
+	}
+
+	return set, errors.Join(errs...)
+}
+
+// parse 把文件解析到模板集合中，模板名为文件路径
+func (s *Set) parse(t *template.Template, name string) error {
+
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return err
+	}
+	s.files[name] = info.ModTime()
+
+	content, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.New(name).Parse(string(content))
+	return err
+}
+
+// stale 模板文件是否有增删或修改
+func (s *Set) stale() bool {
+
+	shared, pages, err := templateFiles(s.fsys)
+	if err != nil || len(shared)+len(pages) != len(s.files) {
+		return true
+	}
+
+	for _, name := range append(shared, pages...) {
+		info, err := fs.Stat(s.fsys, name)
+		if err != nil || !info.ModTime().Equal(s.files[name]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// templateFiles 公共模板（layouts/、partials/ 下的全部 html）和页面模板（顶层的 html 以及 shortcodes/ 下的短代码）
+func templateFiles(fsys fs.FS) (shared, pages []string, err error) {
+
+	for _, dir := range sharedDirs {
+		if info, err := fs.Stat(fsys, dir); err != nil || !info.IsDir() {
+			continue
+		}
+		err = fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".html") {
+				shared = append(shared, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	pages, err = fs.Glob(fsys, "*.html")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	codes, err := fs.Glob(fsys, shortcodeDir+"/*.html")
+
+	return shared, append(pages, codes...), err
+}
+
+// load 返回已编译的模板，尚未编译或调试模式下文件有变化时重新编译
+func load() (*Set, error) {
+
+	setMx.RLock()
+	set, debug := current, watch
+	setMx.RUnlock()
+
+	if set != nil && !(debug && set.stale()) {
+		return set, nil
+	}
+
+	if set == nil {
+		if err := Reload(); err != nil {
+			return nil, err
+		}
+		setMx.RLock()
+		defer setMx.RUnlock()
+		return current, nil
+	}
+
+	set, err := Compile(set.fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	setMx.Lock()
+	current = set
+	setMx.Unlock()
+
+	return set, nil
+}
+
+// Pick 返回 names 中第一个存在的页面模板，忽略空字符串，都不存在时返回最后一个，由 Render 回退到 default.html
+func Pick(names ...string) string {
+
+	var last string
+	set, err := load()
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		last = name
+		if err != nil {
+			continue
+		}
+		if _, ok := set.pages[name]; ok {
+			return name
+		}
+	}
+
+	return last
+}
+
+// Render 使用已编译的模板渲染页面，页面不存在时使用 default.html
+func Render(tplName string, data any) ([]byte, error) {
+
+	set, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	tpl, ok := set.pages[tplName]
+	if !ok {
+		if tpl, ok = set.pages["default.html"]; !ok {
+			return nil, fmt.Errorf("模板 %s 不存在", tplName)
+		}
+	}
+
 	buf := bytes.Buffer{}
 	if err := tpl.Execute(&buf, data); err != nil {
 		return nil, err
@@ -3,7 +3,9 @@ package utils
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"net/url"
 	"os"
+	"strings"
 )
 
 // 生成短链接代码
@@ -16,3 +18,42 @@ func PathExist(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
 }
+
+// NormalizeUrl 规范化url用于去重：忽略协议、域名大小写、www前缀和末尾斜杠
+func NormalizeUrl(raw string) string {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return strings.TrimSpace(raw)
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	normalized := host + strings.TrimRight(u.EscapedPath(), "/")
+	if u.RawQuery != "" {
+		normalized += "?" + u.RawQuery
+	}
+	return normalized
+}
+
+// Slugify 把名称转换为只包含小写字母、数字和 - 的slug，无法转换时返回空字符串
+func Slugify(s string) string {
+
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case b.Len() > 0 && !dash:
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if len(slug) > 64 {
+		slug = strings.TrimRight(slug[:64], "-")
+	}
+
+	return slug
+}
@@ -1,14 +1,17 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
+	"mdnav/internal/cmd"
 	"mdnav/internal/conf"
 	"mdnav/internal/core"
 	"mdnav/internal/pkg/wacher"
 	"mdnav/internal/pkg/zap"
 	"mdnav/internal/router"
 	"mdnav/internal/service"
+	"mdnav/internal/utils/tpl"
 )
 
 var (
@@ -23,6 +26,13 @@ func main() {
 
 	logger.Info("应用启动")
 
+	// debug 参数等同于编译时注入 isDebug=true
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "debug" {
+		isDebug = "true"
+		args = args[1:]
+	}
+
 	if err := conf.InitConfig(".", "config", isDebug); err != nil {
 		logger.Error("配置初始化失败", zap.Error(err))
 		os.Exit(1)
@@ -33,10 +43,39 @@ func main() {
 		Conf: conf.Get(),
 	}
 
+	// 子命令执行完直接退出
+	if code, ok := cmd.Run(ctx, args); ok {
+		logger.Sync()
+		os.Exit(code)
+	} else if len(args) > 0 {
+		fmt.Fprintf(os.Stderr, "未知命令 %q\n\n", args[0])
+		cmd.PrintUsage(os.Stderr)
+		os.Exit(2)
+	}
+
+	// 启动时加载主题并编译模板，有错误时直接退出。预先渲染文档时需要主题中的短代码模板，所以先于数据加载
+	if err := service.LoadTheme(ctx); err != nil {
+		logger.Error("主题加载失败", zap.Error(err))
+		os.Exit(1)
+	}
+	if err := tpl.Init(isDebug == "true"); err != nil {
+		logger.Error("模板编译失败", zap.Error(err))
+		os.Exit(1)
+	}
+
 	if err := service.LoadAllData(ctx); err != nil {
 		os.Exit(1)
 	}
 
+	// 定时发布/过期调度
+	service.StartScheduler(ctx)
+
+	// 网站图标缓存
+	service.InitIcons(ctx)
+
+	// 链接健康检查
+	service.StartLinkChecker(ctx)
+
 	if isDebug == "true" {
 		go wacher.WatcherFile(ctx, func() {
 			logger.Info("文件变化，重新加载文档")
@@ -0,0 +1,9 @@
+// Package themes 内置主题，编译进二进制文件，磁盘上没有对应主题时使用
+package themes
+
+import "embed"
+
+// FS 内置主题，目录结构与 theme.dir 相同
+//
+//go:embed default
+var FS embed.FS